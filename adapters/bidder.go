@@ -3,10 +3,13 @@ package adapters
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/metrics"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
@@ -53,14 +56,57 @@ type TimeoutBidder interface {
 	MakeTimeoutNotification(req *RequestData) (*RequestData, []error)
 }
 
+// RetryBidder is used to identify bidders whose requests are retried once if they time out.
+type RetryBidder interface {
+	Bidder
+
+	// RetryTimeout returns the timeout of the first attempt of the request. If it times out and the remaining
+	// time of the auction is longer than that timeout, the request is sent once more within the remaining time.
+	// A timeout of 0 disables the retry.
+	RetryTimeout(req *RequestData) time.Duration
+}
+
+// FailureAwareBidder is used to identify bidders which are notified of requests failing before MakeBids
+// could be called, e.g. due to a timeout, a network error or a failure status code.
+type FailureAwareBidder interface {
+	Bidder
+
+	// RequestFailed is called once for each request which failed with the given error.
+	RequestFailed(req *RequestData, err error)
+}
+
+// RequestInfoAwareBidder is used to identify bidders which need the ExtraRequestInfo of their requests to make the bids,
+// e.g. to only add debug information to the response if the core emits the debug information of the bidder as well.
+type RequestInfoAwareBidder interface {
+	Bidder
+
+	// MakeBidsWithRequestInfo is called instead of MakeBids with the ExtraRequestInfo given to MakeRequests.
+	MakeBidsWithRequestInfo(internalRequest *openrtb2.BidRequest, externalRequest *RequestData, response *ResponseData, reqInfo *ExtraRequestInfo) (*BidderResponse, []error)
+}
+
+// ErrorResponseBidder is used to identify bidders which handle some responses with a failure status code in MakeBids,
+// e.g. to map the error codes of their server to the error types, rather than failing them with a BadServerResponse.
+type ErrorResponseBidder interface {
+	Bidder
+
+	// HandlesErrorResponse reports whether MakeBids is called for the given response with a failure status code.
+	HandlesErrorResponse(response *ResponseData) bool
+}
+
 // BidderResponse wraps the server's response with the list of bids and the currency used by the bidder.
 //
 // Currency declaration is not mandatory but helps to detect an eventual currency mismatch issue.
 // From the bid response, the bidder accepts a list of valid currencies for the bid.
 // The currency is the same across all bids.
+//
+// BidderResponse.Ext is optional and will become "response.seatbid[i].ext" in the final OpenRTB response.
 type BidderResponse struct {
 	Currency string
 	Bids     []*TypedBid
+	Ext      json.RawMessage
+	// FledgeAuctionConfigs are the Protected Audience auction configs the bidder returned for impressions
+	// requesting an on-device auction. They become response.ext.prebid.fledge.auctionconfigs on the final Response.
+	FledgeAuctionConfigs []*openrtb_ext.FledgeAuctionConfig
 }
 
 // NewBidderResponseWithBidsCapacity create a new BidderResponse initialising the bids array capacity and the default currency value
@@ -96,6 +142,8 @@ type TypedBid struct {
 	BidType      openrtb_ext.BidType
 	BidVideo     *openrtb_ext.ExtBidPrebidVideo
 	DealPriority int
+	// Seat is the seat the bid is grouped under in the response. Bids without seat are grouped under the bidder name.
+	Seat string
 }
 
 // RequestData and ResponseData exist so that prebid-server core code can implement its "debug" functionality
@@ -136,7 +184,31 @@ func (r *RequestData) SetBasicAuth(username string, password string) {
 }
 
 type ExtraRequestInfo struct {
-	PbsEntryPoint metrics.RequestType
+	PbsEntryPoint       metrics.RequestType
+	CurrencyConversions currency.Conversions
+
+	// TraceID is the ID used to trace the auction across systems, if the host set one in the request context
+	TraceID string
+
+	// Deadline is the time by which the bidder has to respond within the auction budget, it's zero if there's none
+	Deadline time.Time
+
+	// Debug is true if the core emits the debug information of the bidder, which requires the request to enable
+	// debug and both the account and the bidder to allow it. Bidders must not add debug information otherwise.
+	Debug bool
+}
+
+// ConvertCurrency converts a given amount from one currency to another, or returns an error
+// if no conversion rates are available or the conversion mapping is unknown to Prebid Server.
+func (r ExtraRequestInfo) ConvertCurrency(value float64, from, to string) (float64, error) {
+	if r.CurrencyConversions == nil {
+		return 0, fmt.Errorf("no currency conversion rates available")
+	}
+	rate, err := r.CurrencyConversions.GetRate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return value * rate, nil
 }
 
 type Builder func(openrtb_ext.BidderName, config.Adapter) (Bidder, error)
@@ -0,0 +1,26 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/currency"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtraRequestInfoConvertCurrency(t *testing.T) {
+	rates := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"EUR": {"USD": 1.2},
+	})
+
+	reqInfo := ExtraRequestInfo{CurrencyConversions: rates}
+	converted, err := reqInfo.ConvertCurrency(2, "EUR", "USD")
+	assert.NoError(t, err)
+	assert.InDelta(t, 2.4, converted, 0.0001)
+
+	_, err = reqInfo.ConvertCurrency(2, "EUR", "JPY")
+	assert.Error(t, err)
+
+	_, err = ExtraRequestInfo{}.ConvertCurrency(2, "EUR", "USD")
+	assert.Error(t, err)
+}
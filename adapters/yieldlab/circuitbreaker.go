@@ -0,0 +1,97 @@
+package yieldlab
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker stops the requests to yieldlab once threshold requests failed within the window. After the
+// cooldown it half-opens and lets a single probe request through, whose outcome closes or opens it again.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	now       clock
+
+	lock     sync.Mutex
+	state    breakerState
+	failures []time.Time
+	// since is the time the breaker opened or sent the latest probe
+	since time.Time
+}
+
+func newCircuitBreaker(threshold int, window time.Duration, cooldown time.Duration, now clock) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		window:    window,
+		cooldown:  cooldown,
+		now:       now,
+	}
+}
+
+// allow checks if a request may be sent. A probe is sent again after another cooldown if the outcome
+// of the previous one never arrived.
+func (b *circuitBreaker) allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == breakerClosed {
+		return true
+	}
+
+	now := b.now()
+	if now.Sub(b.since) < b.cooldown {
+		return false
+	}
+	b.state = breakerHalfOpen
+	b.since = now
+	return true
+}
+
+// recordSuccess closes the breaker if the probe succeeded
+func (b *circuitBreaker) recordSuccess() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.state = breakerClosed
+		b.failures = nil
+	}
+}
+
+// recordFailure opens the breaker if the probe failed or the failures within the window reached the threshold
+func (b *circuitBreaker) recordFailure() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case breakerHalfOpen:
+		b.open(now)
+	case breakerClosed:
+		recent := b.failures[:0]
+		for _, failure := range b.failures {
+			if now.Sub(failure) < b.window {
+				recent = append(recent, failure)
+			}
+		}
+		b.failures = append(recent, now)
+		if len(b.failures) >= b.threshold {
+			b.open(now)
+		}
+	}
+}
+
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = breakerOpen
+	b.since = now
+	b.failures = nil
+}
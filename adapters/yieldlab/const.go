@@ -1,7 +1,57 @@
 package yieldlab
 
+import "math"
+
 const adSlotIdSeparator = ","
+const adslotValueSeparator = ":"
 const adsizeSeparator = "x"
+const adsizeCandidateSeparator = ","
+const maxAdsizeDimension = 10000
 const adSourceBanner = "<script src=\"%v\"></script>"
 const adSourceURL = "https://ad.yieldlab.net/d/%v/%v/%v?%v"
 const creativeID = "%v%v%v"
+const contentJSON = "json"
+const buyerUIDFallbackIFA = "ifa"
+const idProviderSeparator = ":"
+const idsDelimiterDefault = ","
+const priceDenominatorCents = 100
+const priceDenominatorUnits = 1
+const yieldlabVendorID = 70
+const videoContextInstream = "instream"
+const videoContextOutstream = "outstream"
+
+const errorCodeHeader = "X-Yieldlab-Error-Code"
+const errorCodeRateLimited = "rate_limited"
+const errorCodeInvalidAdslot = "invalid_adslot"
+const errorCodeNoConsent = "no_consent"
+
+const roundingModeNearest = "nearest"
+const roundingModeDown = "down"
+const roundingModeUp = "up"
+
+const ipForwardingFull = "full"
+const ipForwardingTruncated = "truncated"
+const ipForwardingNone = "none"
+const ipVersion4 = "v4"
+const ipVersion6 = "v6"
+
+const targetingPrecedenceImp = "imp"
+const targetingPrecedenceConfig = "config"
+const targetingKeyPvid = "hb_yl_pvid"
+
+const dsaRequired = 2
+const dsaRequiredOnlinePlatform = 3
+
+const cacheBusterPlacementQuery = "query"
+const cacheBusterPlacementPath = "path"
+
+const priceTypeNet = "net"
+const priceTypeGross = "gross"
+
+// pidNoDeal is the sentinel Pid yieldlab uses besides 0 for bids that aren't a deal
+const pidNoDeal = math.MaxUint64
+
+const gppSectionSeparator = "~"
+const gppHeaderType = 3
+const gppSectionTCFEUv2 = 2
+const gppMaxSectionID = 1 << 12
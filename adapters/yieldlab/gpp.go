@@ -0,0 +1,135 @@
+package yieldlab
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const gppBase64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// parseGPPSections splits the GPP string into its sections by their section ID. The header, the first section of
+// the string, lists the IDs in the order of the following sections.
+func parseGPPSections(gpp string) (map[int]string, error) {
+	parts := strings.Split(gpp, gppSectionSeparator)
+	ids, err := parseGPPHeader(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != len(parts)-1 {
+		return nil, fmt.Errorf("the GPP header lists %v sections, but the GPP string has %v", len(ids), len(parts)-1)
+	}
+
+	sections := make(map[int]string, len(ids))
+	for i, id := range ids {
+		sections[id] = parts[i+1]
+	}
+	return sections, nil
+}
+
+// parseGPPHeader returns the section IDs of the GPP header. It's a base64url encoded bit field of the type 3,
+// the version and the section IDs as Fibonacci encoded range, whose IDs are offsets to the previous ID.
+func parseGPPHeader(header string) ([]int, error) {
+	r, err := newGPPBitReader(header)
+	if err != nil {
+		return nil, err
+	}
+	if headerType := r.readInt(6); r.err == nil && headerType != gppHeaderType {
+		return nil, fmt.Errorf("unexpected GPP header type %v", headerType)
+	}
+	r.readInt(6) // version
+
+	var ids []int
+	previous := 0
+	for count := r.readInt(12); count > 0 && r.err == nil; count-- {
+		isRange := r.readInt(1) == 1
+		start := previous + r.readFibonacci()
+		end := start
+		if isRange {
+			end = start + r.readFibonacci()
+		}
+		if end > gppMaxSectionID {
+			return nil, fmt.Errorf("implausible GPP section ID %v", end)
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, id)
+		}
+		previous = end
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return ids, nil
+}
+
+// gppBitReader reads the bits of a base64url encoded GPP segment, it keeps the first error
+type gppBitReader struct {
+	bits []bool
+	pos  int
+	err  error
+}
+
+func newGPPBitReader(segment string) (*gppBitReader, error) {
+	bits := make([]bool, 0, len(segment)*6)
+	for i := 0; i < len(segment); i++ {
+		value := strings.IndexByte(gppBase64Alphabet, segment[i])
+		if value < 0 {
+			return nil, fmt.Errorf("invalid GPP character %q", segment[i])
+		}
+		for bit := 5; bit >= 0; bit-- {
+			bits = append(bits, value&(1<<bit) != 0)
+		}
+	}
+	return &gppBitReader{bits: bits}, nil
+}
+
+func (r *gppBitReader) readBit() bool {
+	if r.err != nil {
+		return false
+	}
+	if r.pos >= len(r.bits) {
+		r.err = errors.New("unexpected end of the GPP header")
+		return false
+	}
+	bit := r.bits[r.pos]
+	r.pos++
+	return bit
+}
+
+// readInt reads an unsigned integer of the given number of bits
+func (r *gppBitReader) readInt(bits int) int {
+	value := 0
+	for i := 0; i < bits; i++ {
+		value <<= 1
+		if r.readBit() {
+			value |= 1
+		}
+	}
+	return value
+}
+
+// readFibonacci reads a Fibonacci encoded integer, whose bits stand for the Fibonacci numbers 1, 2, 3, 5, ... and
+// which ends with two consecutive 1 bits
+func (r *gppBitReader) readFibonacci() int {
+	value := 0
+	current, next := 1, 2
+	previousBit := false
+	for r.err == nil {
+		bit := r.readBit()
+		if bit && previousBit {
+			return value
+		}
+		if bit {
+			value += current
+			if value > gppMaxSectionID {
+				r.err = errors.New("implausible GPP section ID")
+				return 0
+			}
+		}
+		previousBit = bit
+		if current <= gppMaxSectionID {
+			current, next = next, current+next
+		}
+	}
+	return 0
+}
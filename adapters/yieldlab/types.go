@@ -1,29 +1,341 @@
 package yieldlab
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"time"
+
+	"github.com/mxmCherry/openrtb/v15/native1"
+
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
 type bidResponse struct {
-	ID         uint64 `json:"id"`
-	Price      uint   `json:"price"`
-	Advertiser string `json:"advertiser"`
-	Adsize     string `json:"adsize"`
-	Pid        uint64 `json:"pid"`
-	Did        uint64 `json:"did"`
-	Pvid       string `json:"pvid"`
+	ID         numericID  `json:"id"`
+	Price      uint       `json:"price"`
+	Advertiser advertiser `json:"advertiser"`
+	Adsize     string     `json:"adsize"`
+	Pid        dealID     `json:"pid"`
+	Did        uint64     `json:"did"`
+	Pvid       string     `json:"pvid"`
+	// Cid is the ID of the campaign of the bid, it's 0 if yieldlab didn't return one
+	Cid uint64 `json:"cid,omitempty"`
+	// Format is the media type yieldlab filled, it's only set for adslots filled in multiple formats
+	Format openrtb_ext.BidType `json:"format,omitempty"`
+	// Native holds the assets of native bids
+	Native *nativeAssets `json:"native,omitempty"`
+	// DSA holds the transparency information of the bid required by the Digital Services Act
+	DSA *dsaResponse `json:"dsa,omitempty"`
+	// Viewability is the predicted probability between 0 and 1 that the ad will be viewable, if yieldlab predicted it
+	Viewability *float64 `json:"viewability,omitempty"`
+	// Currency is the currency of the price, it's only set if a bid isn't priced in EUR
+	Currency string `json:"currency,omitempty"`
+}
+
+// dsaResponse is the DSA transparency information of a bid as defined by the IAB DSA transparency extension
+type dsaResponse struct {
+	Behalf       string            `json:"behalf,omitempty"`
+	Paid         string            `json:"paid,omitempty"`
+	Transparency []dsaTransparency `json:"transparency,omitempty"`
+	// AdRender tells whether the ad renders the DSA information itself, otherwise the publisher does
+	AdRender *int `json:"adrender,omitempty"`
+}
+
+type dsaTransparency struct {
+	Domain string `json:"domain,omitempty"`
+	Params []int  `json:"dsaparams,omitempty"`
+}
+
+// regsExtDSA holds the DSA request of regs.ext.dsa
+type regsExtDSA struct {
+	DSA *dsaRequest `json:"dsa"`
+}
+
+// regsExtGPP holds the GPP string and the applicable GPP section IDs of regs.ext, where OpenRTB 2.5 requests carry them
+type regsExtGPP struct {
+	GPP    string `json:"gpp"`
+	GPPSID []int  `json:"gpp_sid"`
+}
+
+type dsaRequest struct {
+	Required int `json:"dsarequired"`
+	// PubRender is 1 if the publisher renders the DSA information of the ads
+	PubRender int `json:"pubrender"`
+	DataToPub int `json:"datatopub"`
+}
+
+// numericID is an ID yieldlab returns either as number or as numeric string
+type numericID uint64
+
+func (id *numericID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		parsed, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid id %q: %v", s, err)
+		}
+		*id = numericID(parsed)
+		return nil
+	}
+
+	return json.Unmarshal(b, (*uint64)(id))
+}
+
+// dealID is the Pid of a bid, which yieldlab returns either as number or as numeric string. A negative, fractional
+// or out of range Pid isn't a deal, it's read as the sentinel pidNoDeal rather than failing the whole response.
+type dealID uint64
+
+func (id *dealID) UnmarshalJSON(b []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("invalid pid %s: %v", b, err)
+	}
+	if n == "" {
+		return nil
+	}
+
+	pid, err := strconv.ParseUint(n.String(), 10, 64)
+	if err != nil {
+		pid = pidNoDeal
+	}
+	*id = dealID(pid)
+	return nil
+}
+
+// nativeAssets are the assets yieldlab returns for a native bid, which are assembled into the
+// native response requested by the impression
+type nativeAssets struct {
+	Title       string       `json:"title"`
+	Image       *nativeImage `json:"image"`
+	Data        []nativeData `json:"data"`
+	ClickURL    string       `json:"clickUrl"`
+	ImpTrackers []string     `json:"impressionTrackers"`
+}
+
+type nativeImage struct {
+	URL string `json:"url"`
+	W   int64  `json:"w"`
+	H   int64  `json:"h"`
+}
+
+// nativeData is a data asset like the sponsor or the description, its type is one of the native data asset types
+type nativeData struct {
+	Type  native1.DataAssetType `json:"type"`
+	Value string                `json:"value"`
+}
+
+// bidResponseEnvelope is the variant of the yieldlab response which wraps the bids in an object, which may also
+// hold the Protected Audience auction configs of the adslots requesting an on-device auction
+type bidResponseEnvelope struct {
+	Bids           []*bidResponse   `json:"bids"`
+	AuctionConfigs []*auctionConfig `json:"auctionConfigs,omitempty"`
+}
+
+// auctionConfig is the Protected Audience auction config yieldlab returned for an adslot
+type auctionConfig struct {
+	ID     uint64          `json:"id"`
+	Config json.RawMessage `json:"config"`
+}
+
+// userSyncEndpoint is a user sync endpoint configured besides the usersync_url, the URL is a template like it
+type userSyncEndpoint struct {
+	URL  string            `json:"url"`
+	Type adapters.SyncType `json:"type"`
+}
+
+// errorResponse is the body yieldlab returns instead of the bids if it fails to handle the request
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// advertiser is the advertiser of a bid. Yieldlab either returns it as a single string, which is the domain
+// of the advertiser or its numeric ID, or as an object with separate name and domain.
+type advertiser struct {
+	ID     int64  `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Domain string `json:"domain,omitempty"`
+}
+
+// UnmarshalJSON parses the advertiser, which yieldlab returns either as object, as domain or as ID. IDs may be numbers
+// or numeric strings. A numeric value is never taken as domain, it's dropped if it exceeds the range of IDs.
+func (a *advertiser) UnmarshalJSON(b []byte) error {
+	var number json.Number
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] != '"' && json.Unmarshal(trimmed, &number) == nil {
+		*a = advertiser{}
+		if number == "" {
+			return nil
+		}
+		if !isDigits(number.String()) {
+			return fmt.Errorf("invalid advertiser ID %v", number)
+		}
+		a.ID = parseAdvertiserID(number.String())
+		return nil
+	}
+
+	var domain string
+	if err := json.Unmarshal(b, &domain); err == nil {
+		if isDigits(domain) {
+			*a = advertiser{ID: parseAdvertiserID(domain)}
+		} else {
+			*a = advertiser{Domain: domain}
+		}
+		return nil
+	}
+
+	type plain advertiser
+	return json.Unmarshal(b, (*plain)(a))
+}
+
+// parseAdvertiserID parses the digits of an advertiser ID, it's 0 if the ID exceeds the range of IDs
+func parseAdvertiserID(digits string) int64 {
+	id, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// isDigits checks if the value consists of decimal digits only
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// bidExt is the bid.ext of the bids returned by the adapter
+type bidExt struct {
+	// Did is the yieldlab deal ID of the bid which, unlike the pid used as bid.dealid, is meant for reporting
+	Did string `json:"did,omitempty"`
+	// OrigBidCPM and OrigBidCur hold the price of the bid before it was converted into the response currency
+	OrigBidCPM float64 `json:"origbidcpm,omitempty"`
+	OrigBidCur string  `json:"origbidcur,omitempty"`
+	// PriceType is either net or gross if it's configured which prices yieldlab returns
+	PriceType string `json:"pricetype,omitempty"`
+
+	// VideoContext is either instream or outstream for video bids of impressions with a placement
+	VideoContext string `json:"context,omitempty"`
+
+	Prebid *bidExtPrebid `json:"prebid,omitempty"`
+
+	// Targeting holds key values the publisher may add to the targeting of the bid. They are hints only, prebid
+	// server doesn't add them to bid.ext.prebid.targeting.
+	Targeting map[string]string `json:"targeting,omitempty"`
+
+	// DSA is the DSA transparency information exposed for the publisher to render
+	DSA *dsaResponse `json:"dsa,omitempty"`
+
+	// Debug holds the bid as parsed from the yieldlab response, it's only set if the core emits the debug information of the bidder
+	Debug *bidDebug `json:"debug,omitempty"`
+}
+
+// bidDebug is the debug output of a bid
+type bidDebug struct {
+	*bidResponse
+	// RequestURL is the URL the bid was requested from
+	RequestURL string `json:"requestUrl"`
+}
+
+// seatBidExt is the seatbid.ext of the bids returned by the adapter
+type seatBidExt struct {
+	Advertisers advertiserSummary `json:"advertisers,omitempty"`
+	// Debug is only set if the core emits the debug information of the bidder
+	Debug *seatBidDebug `json:"debug,omitempty"`
+}
+
+// seatBidDebug is the debug output of a response
+type seatBidDebug struct {
+	// NoBidAdslots are the requested adslots yieldlab returned no bid for
+	NoBidAdslots []string `json:"noBidAdslots"`
+	// RequestBytes is the size of the request to yieldlab, which is its URL and body
+	RequestBytes int `json:"requestBytes"`
+	// ResponseBytes is the size of the response body of yieldlab
+	ResponseBytes int `json:"responseBytes"`
+}
+
+// advertiserSummary lists the advertisers of the bids in the order of their first bid
+type advertiserSummary []advertiserBids
+
+type advertiserBids struct {
+	ID     int64  `json:"id,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	Bids   int    `json:"bids"`
+}
+
+// add counts a bid of the advertiser, bids without advertiser aren't counted
+func (s *advertiserSummary) add(adv advertiser) {
+	if adv == (advertiser{}) {
+		return
+	}
+	for i := range *s {
+		if entry := &(*s)[i]; entry.ID == adv.ID && entry.Name == adv.Name && entry.Domain == adv.Domain {
+			entry.Bids++
+			return
+		}
+	}
+	*s = append(*s, advertiserBids{ID: adv.ID, Name: adv.Name, Domain: adv.Domain, Bids: 1})
+}
+
+// bidExtPrebid is the bid.ext.prebid of the bids, which is merged with the one of prebid server
+type bidExtPrebid struct {
+	Meta *openrtb_ext.ExtBidPrebidMeta `json:"meta,omitempty"`
+}
+
+// impExt holds the parts of imp.ext read by the adapter besides the bidder params
+type impExt struct {
+	TID string `json:"tid"`
+	// AE is 1 if the impression requests a Protected Audience on-device auction
+	AE     int                        `json:"ae"`
+	Bidder openrtb_ext.ExtImpYieldlab `json:"bidder"`
+	Prebid impExtPrebid               `json:"prebid"`
+}
+
+// impExtPrebid holds the parts of imp.ext.prebid read by the adapter
+type impExtPrebid struct {
+	// AdUnitCode is the code of the publisher's ad unit the impression belongs to
+	AdUnitCode string `json:"adunitcode"`
+}
+
+// adSourceOptions are the options of the URL an ad is rendered from
+type adSourceOptions struct {
+	// exp is the number of seconds after which the bid expires. The unix time of the expiry is added,
+	// so renders of stale bids can be detected.
+	exp int64
+	// renderDSA lets the ad render the DSA transparency information itself
+	renderDSA bool
+	// cacheable omits the cache buster and the expiry, so the ad can be served from the cache as AMP requires
+	cacheable bool
 }
 
 type cacheBuster func() string
 
 type weekGenerator func() string
 
+type clock func() time.Time
+
 var defaultCacheBuster cacheBuster = func() string {
 	return strconv.FormatInt(time.Now().Unix(), 10)
 }
 
-var defaultWeekGenerator weekGenerator = func() string {
-	_, week := time.Now().ISOWeek()
-	return strconv.Itoa(week)
+var defaultClock clock = time.Now
+
+// newWeekGenerator returns a weekGenerator which generates the ISO week of the clock in the location, so the week
+// doesn't depend on the local time of the server
+func newWeekGenerator(now clock, location *time.Location) weekGenerator {
+	return func() string {
+		_, week := now().In(location).ISOWeek()
+		return strconv.Itoa(week)
+	}
 }
@@ -1,12 +1,137 @@
 package yieldlab
 
 import (
+	"fmt"
+	"net/url"
 	"text/template"
 
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/macros"
+	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/usersync"
 )
 
+// syncRedirectParams are the query parameters of the sync URL which may hold the redirect to the setuid endpoint
+var syncRedirectParams = []string{"r", "redirectUri"}
+
 func NewYieldlabSyncer(temp *template.Template) usersync.Usersyncer {
-	return adapters.NewSyncer("yieldlab", temp, adapters.SyncTypeRedirect)
+	return NewYieldlabSyncerFactory("")(temp)
+}
+
+// NewYieldlabSyncerFactory returns the factory of the yieldlab syncer, which syncs the additional user sync endpoints
+// of the adapter's extra info besides the one of the template
+func NewYieldlabSyncerFactory(extraAdapterInfo string) func(*template.Template) usersync.Usersyncer {
+	return func(temp *template.Template) usersync.Usersyncer {
+		syncer := &yieldlabSyncer{Syncer: adapters.NewSyncer("yieldlab", temp, adapters.SyncTypeRedirect)}
+
+		// an invalid extra info fails the build of the adapter, so it's only skipped here
+		extraInfo, err := getExtraInfo(extraAdapterInfo)
+		if err != nil {
+			return syncer
+		}
+		for i, endpoint := range extraInfo.AdditionalUserSyncs {
+			additionalTemp, err := template.New(fmt.Sprintf("yieldlab_usersync_url_%v", i)).Parse(endpoint.URL)
+			if err != nil {
+				continue
+			}
+			syncer.additional = append(syncer.additional, adapters.NewSyncer("yieldlab", additionalTemp, endpoint.Type))
+		}
+		return syncer
+	}
+}
+
+// yieldlabSyncer passes the GDPR signal to the sync URL as either "1" if GDPR applies, "0" if it doesn't
+// or empty if it's unknown, as yieldlab treats an empty gdpr differently from gdpr=0
+type yieldlabSyncer struct {
+	*adapters.Syncer
+	// additional are the syncers of the endpoints synced besides the main one
+	additional []*adapters.Syncer
+}
+
+// KeepsAmbiguousGDPRSignal returns true, so the gdpr of the sync URL is empty if it's unknown whether GDPR applies
+func (s *yieldlabSyncer) KeepsAmbiguousGDPRSignal() bool {
+	return true
+}
+
+func (s *yieldlabSyncer) GetUsersyncInfo(privacyPolicies privacy.Policies) (*usersync.UsersyncInfo, error) {
+	return s.Syncer.GetUsersyncInfo(normalizeGDPRSignal(privacyPolicies))
+}
+
+// GetUsersyncInfos returns the info of the main sync endpoint followed by the additional ones
+func (s *yieldlabSyncer) GetUsersyncInfos(privacyPolicies privacy.Policies) ([]*usersync.UsersyncInfo, error) {
+	privacyPolicies = normalizeGDPRSignal(privacyPolicies)
+
+	syncInfo, err := s.Syncer.GetUsersyncInfo(privacyPolicies)
+	if err != nil {
+		return nil, err
+	}
+	syncInfos := []*usersync.UsersyncInfo{syncInfo}
+	for _, syncer := range s.additional {
+		if syncInfo, err = syncer.GetUsersyncInfo(privacyPolicies); err != nil {
+			return nil, err
+		}
+		syncInfos = append(syncInfos, syncInfo)
+	}
+	return syncInfos, nil
+}
+
+func normalizeGDPRSignal(privacyPolicies privacy.Policies) privacy.Policies {
+	switch privacyPolicies.GDPR.Signal {
+	case "0", "1":
+	default:
+		privacyPolicies.GDPR.Signal = ""
+	}
+	return privacyPolicies
+}
+
+// validateUserSyncURL renders the user sync URL template with dummy values and checks that both the
+// rendered URL and the redirect it contains are absolute URLs, so malformed templates fail at startup
+func validateUserSyncURL(userSyncURL string) error {
+	if userSyncURL == "" {
+		return nil
+	}
+
+	temp, err := template.New("yieldlab_usersync_url").Parse(userSyncURL)
+	if err != nil {
+		return fmt.Errorf("invalid yieldlab user sync URL template: %v", err)
+	}
+
+	rendered, err := macros.ResolveMacros(*temp, macros.UserSyncTemplateParams{
+		GDPR:        "0",
+		GDPRConsent: "dummyGDPRConsent",
+		USPrivacy:   "1NYN",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to render yieldlab user sync URL: %v", err)
+	}
+
+	syncURL, err := parseAbsoluteURL(rendered)
+	if err != nil {
+		return fmt.Errorf("invalid yieldlab user sync URL %q: %v", rendered, err)
+	}
+
+	query, err := url.ParseQuery(syncURL.RawQuery)
+	if err != nil {
+		return fmt.Errorf("invalid yieldlab user sync URL %q: %v", rendered, err)
+	}
+	for _, param := range syncRedirectParams {
+		if redirect := query.Get(param); redirect != "" {
+			if _, err := parseAbsoluteURL(redirect); err != nil {
+				return fmt.Errorf("invalid redirect %q of yieldlab user sync URL: %v", redirect, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseAbsoluteURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("scheme and host are required")
+	}
+	return u, nil
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/privacy/gdpr"
+	"github.com/prebid/prebid-server/usersync"
 )
 
 func TestYieldlabSyncer(t *testing.T) {
@@ -23,3 +24,109 @@ func TestYieldlabSyncer(t *testing.T) {
 	assert.Equal(t, "redirect", syncInfo.Type)
 	assert.False(t, syncInfo.SupportCORS)
 }
+
+func TestYieldlabSyncer_gdprSignal(t *testing.T) {
+	temp := template.Must(template.New("sync-template").Parse("https://ad.yieldlab.net/mr?t=2&pid=9140838&gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}"))
+	syncer := NewYieldlabSyncer(temp)
+
+	tests := []struct {
+		name    string
+		policy  gdpr.Policy
+		wantURL string
+	}{
+		{
+			name:    "applies",
+			policy:  gdpr.Policy{Signal: "1", Consent: "BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw"},
+			wantURL: "https://ad.yieldlab.net/mr?t=2&pid=9140838&gdpr=1&gdpr_consent=BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw",
+		},
+		{
+			name:    "does_not_apply",
+			policy:  gdpr.Policy{Signal: "0"},
+			wantURL: "https://ad.yieldlab.net/mr?t=2&pid=9140838&gdpr=0&gdpr_consent=",
+		},
+		{
+			name:    "unknown",
+			policy:  gdpr.Policy{Signal: ""},
+			wantURL: "https://ad.yieldlab.net/mr?t=2&pid=9140838&gdpr=&gdpr_consent=",
+		},
+		{
+			name:    "invalid",
+			policy:  gdpr.Policy{Signal: "2"},
+			wantURL: "https://ad.yieldlab.net/mr?t=2&pid=9140838&gdpr=&gdpr_consent=",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncInfo, err := syncer.GetUsersyncInfo(privacy.Policies{GDPR: tt.policy})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantURL, syncInfo.URL)
+		})
+	}
+}
+
+func TestYieldlabSyncer_additionalUserSyncs(t *testing.T) {
+	temp := template.Must(template.New("sync-template").Parse("https://ad.yieldlab.net/mr?t=2&gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}"))
+	syncer := NewYieldlabSyncerFactory(`{"additional_user_syncs":[{"url":"https://ad.yieldlab.net/px?gdpr={{.GDPR}}","type":"iframe"}]}`)(temp)
+
+	multiSyncer, ok := syncer.(usersync.MultiUsersyncer)
+	if !assert.True(t, ok) {
+		return
+	}
+	syncInfos, err := multiSyncer.GetUsersyncInfos(privacy.Policies{GDPR: gdpr.Policy{Signal: "0"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*usersync.UsersyncInfo{
+		{URL: "https://ad.yieldlab.net/mr?t=2&gdpr=0&gdpr_consent=", Type: "redirect"},
+		{URL: "https://ad.yieldlab.net/px?gdpr=0", Type: "iframe"},
+	}, syncInfos)
+}
+
+func TestValidateUserSyncURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			url:  "https://ad.yieldlab.net/mr?t=2&pid=9140838&gdpr={{.GDPR}}&gdpr_consent={{.GDPRConsent}}&r=http%3A%2F%2Flocalhost%3A8000%2Fsetuid%3Fbidder%3Dyieldlab%26uid%3D%25%25YL_UID%25%25",
+		},
+		{
+			name:    "invalid_template",
+			url:     "https://ad.yieldlab.net/mr?gdpr={{.GDPR}",
+			wantErr: "invalid yieldlab user sync URL template",
+		},
+		{
+			name:    "unknown_macro",
+			url:     "https://ad.yieldlab.net/mr?gdpr={{.Unknown}}",
+			wantErr: "failed to render yieldlab user sync URL",
+		},
+		{
+			name:    "relative_url",
+			url:     "ad.yieldlab.net/mr?gdpr={{.GDPR}}",
+			wantErr: `invalid yieldlab user sync URL "ad.yieldlab.net/mr?gdpr=0"`,
+		},
+		{
+			name:    "relative_redirect",
+			url:     "https://ad.yieldlab.net/mr?gdpr={{.GDPR}}&r=%2Fsetuid%3Fbidder%3Dyieldlab",
+			wantErr: `invalid redirect "/setuid?bidder=yieldlab" of yieldlab user sync URL`,
+		},
+		{
+			name:    "malformed_redirect",
+			url:     "https://ad.yieldlab.net/mr?gdpr={{.GDPR}}&redirectUri=http%3A%2F%2Flocalhost%2F%25zz",
+			wantErr: `invalid redirect "http://localhost/%zz" of yieldlab user sync URL`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateUserSyncURL(tt.url)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,21 +1,38 @@
 package yieldlab
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/text/currency"
 
+	"github.com/mxmCherry/openrtb/v15/native1"
+	nativeRequests "github.com/mxmCherry/openrtb/v15/native1/request"
+	nativeResponse "github.com/mxmCherry/openrtb/v15/native1/response"
 	"github.com/mxmCherry/openrtb/v15/openrtb2"
+	"github.com/prebid/go-gdpr/consentconstants"
+	tcf2ConsentConstants "github.com/prebid/go-gdpr/consentconstants/tcf2"
+	"github.com/prebid/go-gdpr/vendorconsent"
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/config"
+	pbscurrency "github.com/prebid/prebid-server/currency"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy"
 )
 
 // YieldlabAdapter connects the Yieldlab API to prebid server
@@ -23,238 +40,1761 @@ type YieldlabAdapter struct {
 	endpoint    string
 	cacheBuster cacheBuster
 	getWeek     weekGenerator
+	now         clock
+	extraInfo   ExtraInfo
+
+	// conversions holds the currency rates of the latest auction, as MakeBids has no access to the ExtraRequestInfo
+	conversionsLock sync.RWMutex
+	conversions     pbscurrency.Conversions
+
+	// breaker skips requests after repeated failures, it's nil if the circuit breaker isn't configured
+	breaker *circuitBreaker
+}
+
+// ExtraInfo holds the optional adapter settings given in the adapter's extra_info config
+type ExtraInfo struct {
+	// PreferMediaType is the media type bid for impressions offering both banner and video, either "video" (default) or "banner"
+	PreferMediaType openrtb_ext.BidType `json:"prefer_media_type,omitempty"`
+	// AllowedAdslotIDs restricts the adslots which may be requested, all adslots are allowed if empty
+	AllowedAdslotIDs []string `json:"allowed_adslot_ids,omitempty"`
+	// BidTTL is the default bid.exp in seconds for impressions without imp.exp
+	BidTTL int64 `json:"bid_ttl,omitempty"`
+	// StripRefererQuery removes the query string and fragment of the page sent as referer, as they may contain personal data
+	StripRefererQuery bool `json:"strip_referer_query,omitempty"`
+	// SigningSecret is the secret shared with yieldlab to sign the request query, requests aren't signed if empty
+	SigningSecret string `json:"signing_secret,omitempty"`
+	// CacheBusterSalt is appended to the ts cache buster to separate the cache entries of tenants sharing a proxy
+	CacheBusterSalt string `json:"cache_buster_salt,omitempty"`
+	// BuyerUIDFallback is the id sent instead of an empty buyeruid if privacy permits, either none (default) or "ifa"
+	BuyerUIDFallback string `json:"buyer_uid_fallback,omitempty"`
+	// StrictResponseParsing rejects responses with unknown fields to detect changes of the yieldlab response early
+	StrictResponseParsing bool `json:"strict_response_parsing,omitempty"`
+	// AllowTrailingResponseData parses the bids of responses with data after them with a warning instead of failing
+	AllowTrailingResponseData bool `json:"allow_trailing_response_data,omitempty"`
+	// RetryTimeoutMs is the timeout of the first request in milliseconds, after which it's retried once within tmax.
+	// Requests aren't retried if it's 0.
+	RetryTimeoutMs int64 `json:"retry_timeout_ms,omitempty"`
+	// CreativeIDTemplate is the format of bid.crid, which defaults to the adslot ID, pid and week concatenated.
+	// The placeholders {adslotId}, {supplyId}, {pid}, {did} and {week} are replaced by the values of the bid.
+	CreativeIDTemplate string `json:"creative_id_template,omitempty"`
+	// RoundingMode rounds prices converted into another currency to cents, either "nearest", "down" or "up".
+	// Converted prices aren't rounded if it's empty.
+	RoundingMode string `json:"rounding_mode,omitempty"`
+	// IPForwarding is the device IP sent as X-Forwarded-For, either "full" (default), "truncated" or "none"
+	IPForwarding string `json:"ip_forwarding,omitempty"`
+	// IPVersion is the IP version forwarded if the device has both an IPv4 and IPv6, either "v4" (default) or "v6"
+	IPVersion string `json:"ip_version,omitempty"`
+	// MaxBids is the maximum number of bids processed per response, the excess bids are dropped. There is no limit if it's 0.
+	MaxBids int `json:"max_bids,omitempty"`
+	// DefaultTargeting is the targeting sent for every adslot in addition to the targeting of the impressions
+	DefaultTargeting map[string]string `json:"default_targeting,omitempty"`
+	// TargetingPrecedence decides which value is sent if the default targeting and the impression set the same key,
+	// either "imp" (default) or "config"
+	TargetingPrecedence string `json:"targeting_precedence,omitempty"`
+	// FallbackRates are static currency rates, mapping from a currency to others, which are only used if
+	// the currency rates of the auction lack the conversion
+	FallbackRates map[string]map[string]float64 `json:"fallback_rates,omitempty"`
+	// RequestMethods maps media types to the HTTP method of the request, either "GET" (default) or "POST".
+	// The request is a POST with the query as form body if any impression has a media type mapped to POST.
+	RequestMethods map[openrtb_ext.BidType]string `json:"request_methods,omitempty"`
+	// AdvertiserSummary adds a summary of the advertisers of the bids to the seatbid.ext for reporting
+	AdvertiserSummary bool `json:"advertiser_summary,omitempty"`
+	// SeatPerAdvertiser groups the bids into a seat per advertiser instead of the seat of the bidder. Bids without
+	// advertiser stay in the seat of the bidder.
+	SeatPerAdvertiser bool `json:"seat_per_advertiser,omitempty"`
+	// CircuitBreakerThreshold is the number of failed requests within the circuit breaker window after which
+	// requests are skipped for the cooldown, before a single probe request is sent. It's disabled if it's 0.
+	CircuitBreakerThreshold  int   `json:"circuit_breaker_threshold,omitempty"`
+	CircuitBreakerWindowMs   int64 `json:"circuit_breaker_window_ms,omitempty"`
+	CircuitBreakerCooldownMs int64 `json:"circuit_breaker_cooldown_ms,omitempty"`
+	// OmitQueryParams are query parameters of the request which aren't sent to yieldlab. They are removed before
+	// the query is signed.
+	OmitQueryParams []string `json:"omit_query_params,omitempty"`
+	// DefaultDeviceType is the device type sent if the request has no device or device type
+	DefaultDeviceType openrtb2.DeviceType `json:"default_device_type,omitempty"`
+	// OmitEmptyDeviceParams omits yl_rtb_ifa and yl_rtb_devicetype instead of sending them empty or as 0 if the
+	// device has no advertising id or the device type is unknown
+	OmitEmptyDeviceParams bool `json:"omit_empty_device_params,omitempty"`
+	// ConsentCookie adds the consent string to the cookie header if GDPR applies and the user gave one
+	ConsentCookie bool `json:"consent_cookie,omitempty"`
+	// PriceDenominator is the number the prices returned by yieldlab are divided by, either 100 (default) for prices
+	// in cents or 1 for prices in whole currency units
+	PriceDenominator int `json:"price_denominator,omitempty"`
+	// CacheBusterPlacement is where the cache buster of the request is sent, either as ts parameter of the
+	// "query" (default) or as last segment of the "path"
+	CacheBusterPlacement string `json:"cache_buster_placement,omitempty"`
+	// AdditionalUserSyncs are user sync endpoints synced besides the usersync_url, like a pixel besides a redirect
+	AdditionalUserSyncs []userSyncEndpoint `json:"additional_user_syncs,omitempty"`
+	// Content is the content query parameter of the request, which defaults to "json". Other values like "test"
+	// are meant for mock servers, as the response is always parsed as the JSON yieldlab returns for "json".
+	Content string `json:"content,omitempty"`
+	// VideoPvidTargeting adds the pvid of video bids as hb_yl_pvid hint to bid.ext.targeting, so publishers can add it
+	// to the targeting of cached video bids to deduplicate them in the ad server
+	VideoPvidTargeting bool `json:"video_pvid_targeting,omitempty"`
+	// DropBidsWithoutDSA drops bids without DSA transparency information if the request requires it
+	DropBidsWithoutDSA bool `json:"drop_bids_without_dsa,omitempty"`
+	// MinViewability drops bids whose predicted viewability, a probability between 0 and 1, is below it.
+	// Bids without prediction are kept.
+	MinViewability float64 `json:"min_viewability,omitempty"`
+	// PriceType states whether the prices returned by yieldlab are "net" or "gross" prices, so revenue shares are
+	// only applied to gross prices. It isn't stated on the bids if it's empty.
+	PriceType string `json:"price_type,omitempty"`
+	// WeekTimezone is the IANA time zone the week of the creative IDs is computed in, which defaults to UTC
+	WeekTimezone string `json:"week_timezone,omitempty"`
+	// TransactionIDHeader is the name of a header the source.tid is sent in besides the tid parameter, e.g.
+	// "X-Transaction-Id", so the trace logs of yieldlab can be correlated with the auction
+	TransactionIDHeader string `json:"transaction_id_header,omitempty"`
+	// IDsDelimiter separates the provider:id entries of the ids parameter, which defaults to ","
+	IDsDelimiter string `json:"ids_delimiter,omitempty"`
+	// IDsIncludeIFA sends the IFA as ifa entry of the ids parameter besides the buyeruid if privacy permits
+	IDsIncludeIFA bool `json:"ids_include_ifa,omitempty"`
 }
 
 // Builder builds a new instance of the Yieldlab adapter for the given bidder with the given config.
 func Builder(bidderName openrtb_ext.BidderName, config config.Adapter) (adapters.Bidder, error) {
+	extraInfo, err := getExtraInfo(config.ExtraAdapterInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUserSyncURL(config.UserSyncURL); err != nil {
+		return nil, err
+	}
+
+	weekLocation, err := time.LoadLocation(extraInfo.WeekTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extra info: unsupported week_timezone %q", extraInfo.WeekTimezone)
+	}
+
 	bidder := &YieldlabAdapter{
 		endpoint:    config.Endpoint,
 		cacheBuster: defaultCacheBuster,
-		getWeek:     defaultWeekGenerator,
+		getWeek:     newWeekGenerator(defaultClock, weekLocation),
+		now:         defaultClock,
+		extraInfo:   extraInfo,
+	}
+	if extraInfo.CircuitBreakerThreshold > 0 {
+		bidder.breaker = newCircuitBreaker(extraInfo.CircuitBreakerThreshold,
+			time.Duration(extraInfo.CircuitBreakerWindowMs)*time.Millisecond,
+			time.Duration(extraInfo.CircuitBreakerCooldownMs)*time.Millisecond,
+			defaultClock)
 	}
 	return bidder, nil
 }
 
+func getExtraInfo(v string) (ExtraInfo, error) {
+	var extraInfo ExtraInfo
+	if len(v) == 0 {
+		return extraInfo, nil
+	}
+
+	if err := json.Unmarshal([]byte(v), &extraInfo); err != nil {
+		return extraInfo, fmt.Errorf("invalid extra info: %v", err)
+	}
+
+	switch extraInfo.PreferMediaType {
+	case "", openrtb_ext.BidTypeVideo, openrtb_ext.BidTypeBanner:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported prefer_media_type %q", extraInfo.PreferMediaType)
+	}
+
+	switch extraInfo.RoundingMode {
+	case "", roundingModeNearest, roundingModeDown, roundingModeUp:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported rounding_mode %q", extraInfo.RoundingMode)
+	}
+
+	switch extraInfo.IPForwarding {
+	case "", ipForwardingFull, ipForwardingTruncated, ipForwardingNone:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported ip_forwarding %q", extraInfo.IPForwarding)
+	}
+
+	switch extraInfo.IPVersion {
+	case "", ipVersion4, ipVersion6:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported ip_version %q", extraInfo.IPVersion)
+	}
+
+	switch extraInfo.TargetingPrecedence {
+	case "", targetingPrecedenceImp, targetingPrecedenceConfig:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported targeting_precedence %q", extraInfo.TargetingPrecedence)
+	}
+
+	for mediaType, method := range extraInfo.RequestMethods {
+		switch method {
+		case http.MethodGet, http.MethodPost:
+		default:
+			return extraInfo, fmt.Errorf("invalid extra info: unsupported request_methods %q for %v", method, mediaType)
+		}
+	}
+
+	if extraInfo.CircuitBreakerThreshold > 0 && (extraInfo.CircuitBreakerWindowMs <= 0 || extraInfo.CircuitBreakerCooldownMs <= 0) {
+		return extraInfo, errors.New("invalid extra info: the circuit breaker requires circuit_breaker_window_ms and circuit_breaker_cooldown_ms")
+	}
+
+	switch extraInfo.CacheBusterPlacement {
+	case "", cacheBusterPlacementQuery, cacheBusterPlacementPath:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported cache_buster_placement %q", extraInfo.CacheBusterPlacement)
+	}
+
+	if strings.Contains(extraInfo.IDsDelimiter, idProviderSeparator) {
+		return extraInfo, fmt.Errorf("invalid extra info: ids_delimiter %q contains the separator of the provider", extraInfo.IDsDelimiter)
+	}
+
+	switch extraInfo.PriceType {
+	case "", priceTypeNet, priceTypeGross:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported price_type %q", extraInfo.PriceType)
+	}
+
+	for _, endpoint := range extraInfo.AdditionalUserSyncs {
+		switch endpoint.Type {
+		case adapters.SyncTypeRedirect, adapters.SyncTypeIframe:
+		default:
+			return extraInfo, fmt.Errorf("invalid extra info: unsupported additional_user_syncs type %q", endpoint.Type)
+		}
+		if endpoint.URL == "" {
+			return extraInfo, errors.New("invalid extra info: additional_user_syncs require a url")
+		}
+		if err := validateUserSyncURL(endpoint.URL); err != nil {
+			return extraInfo, fmt.Errorf("invalid extra info: %v", err)
+		}
+	}
+
+	if extraInfo.MinViewability < 0 || extraInfo.MinViewability > 1 {
+		return extraInfo, fmt.Errorf("invalid extra info: min_viewability %v isn't between 0 and 1", extraInfo.MinViewability)
+	}
+
+	switch extraInfo.PriceDenominator {
+	case 0, priceDenominatorCents, priceDenominatorUnits:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported price_denominator %v", extraInfo.PriceDenominator)
+	}
+
+	switch extraInfo.BuyerUIDFallback {
+	case "", buyerUIDFallbackIFA:
+	default:
+		return extraInfo, fmt.Errorf("invalid extra info: unsupported buyer_uid_fallback %q", extraInfo.BuyerUIDFallback)
+	}
+
+	return extraInfo, nil
+}
+
 // Builds endpoint url based on adapter-specific pub settings from imp.ext
-func (a *YieldlabAdapter) makeEndpointURL(req *openrtb2.BidRequest, params *openrtb_ext.ExtImpYieldlab) (string, error) {
+func (a *YieldlabAdapter) makeEndpointURL(req *openrtb2.BidRequest, params *openrtb_ext.ExtImpYieldlab, reqInfo *adapters.ExtraRequestInfo) (string, error) {
 	uri, err := url.Parse(a.endpoint)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse yieldlab endpoint: %v", err)
+		return "", &errortypes.FailedToRequestBids{
+			Message: fmt.Sprintf("failed to parse yieldlab endpoint: %v", err),
+		}
 	}
 
-	uri.Path = path.Join(uri.Path, params.AdslotID)
+	if uri.Scheme == "http" && requiresSecure(req) {
+		uri.Scheme = "https"
+	}
+	if uri.Path, err = appendPathSegment(uri.Path, params.AdslotID); err != nil {
+		return "", err
+	}
 	q := uri.Query()
-	q.Set("content", "json")
+	q.Set("content", a.getContent())
 	q.Set("pvid", "true")
-	q.Set("ts", a.cacheBuster())
+	if a.extraInfo.CacheBusterPlacement == cacheBusterPlacementPath {
+		if uri.Path, err = appendPathSegment(uri.Path, a.makeRequestCacheBuster(reqInfo.TraceID)); err != nil {
+			return "", err
+		}
+	} else {
+		q.Set("ts", a.makeRequestCacheBuster(reqInfo.TraceID))
+	}
 	q.Set("t", a.makeTargetingValues(params))
 
+	if req.Test == 1 {
+		q.Set("testmode", "1")
+	}
+	// tmax tells yieldlab how many milliseconds are left to respond within the budget of the auction
+	if !reqInfo.Deadline.IsZero() {
+		q.Set("tmax", strconv.FormatInt(reqInfo.Deadline.Sub(a.now()).Milliseconds(), 10))
+	}
+
+	if req.Source != nil && req.Source.TID != "" {
+		q.Set("tid", req.Source.TID)
+	}
+	if schain := makeSupplyChain(req); schain != "" {
+		q.Set("schain", schain)
+	}
+	if impTIDs := makeImpTransactionIDs(req); impTIDs != "" {
+		q.Set("imptids", impTIDs)
+	}
+	if metrics := makeImpMetrics(req); metrics != "" {
+		q.Set("metrics", metrics)
+	}
+	if placements := makeVideoPlacements(req); placements != "" {
+		q.Set("placement", placements)
+	}
+	if adUnitCodes := makeAdUnitCodes(req); adUnitCodes != "" {
+		q.Set("adunitcode", adUnitCodes)
+	}
+	if ae := makeAuctionEnvironments(req); ae != "" {
+		q.Set("ae", ae)
+	}
+	if floors := a.makeFloors(req); floors != "" {
+		q.Set("floors", floors)
+	}
+
+	if len(req.WSeat) > 0 {
+		q.Set("wseat", strings.Join(req.WSeat, ","))
+	}
+	if len(req.WLang) > 0 {
+		q.Set("wlang", strings.Join(req.WLang, ","))
+	}
+
+	if contentURL := getContentURL(req); contentURL != "" {
+		q.Set("contenturl", contentURL)
+	}
+
+	gdpr, consent, err := a.getGDPR(req)
+	if err != nil {
+		return "", err
+	}
+	if gdpr != "" && consent != "" {
+		q.Set("gdpr", gdpr)
+		q.Set("consent", consent)
+	}
+	if gpp, gppSID := getGPP(req); gpp != "" {
+		q.Set("gpp", gpp)
+		if gppSID != "" {
+			q.Set("gpp_sid", gppSID)
+		}
+	}
+
+	if req.Device != nil {
+		ifa := req.Device.IFA
+		if limitsAdTracking(req.Device) || lacksVendorConsent(gdpr, consent) {
+			ifa = ""
+		}
+		if ifa != "" || !a.extraInfo.OmitEmptyDeviceParams {
+			q.Set("yl_rtb_ifa", ifa)
+		}
+
+		if req.Device.ConnectionType != nil {
+			q.Set("yl_rtb_connectiontype", fmt.Sprintf("%v", req.Device.ConnectionType.Val()))
+		}
+		if req.Device.PxRatio != 0 {
+			q.Set("yl_rtb_pxratio", strconv.FormatFloat(req.Device.PxRatio, 'f', -1, 64))
+		}
+	}
+	if deviceType := a.getDeviceType(req); deviceType != 0 || (req.Device != nil && !a.extraInfo.OmitEmptyDeviceParams) {
+		q.Set("yl_rtb_devicetype", fmt.Sprintf("%v", deviceType))
+	}
+
+	if geo := getGeo(req); geo != nil {
+		q.Set("lat", fmt.Sprintf("%v", geo.Lat))
+		q.Set("lon", fmt.Sprintf("%v", geo.Lon))
+	}
+
+	if req.App != nil {
+		q.Set("pubappname", req.App.Name)
+		q.Set("pubbundlename", req.App.Bundle)
+
+		if req.App.Paid != 0 {
+			q.Set("pubapppaid", strconv.Itoa(int(req.App.Paid)))
+		}
+
+		if req.Device != nil && req.Device.Carrier != "" {
+			q.Set("yl_rtb_carrier", req.Device.Carrier)
+		}
+		if req.Device != nil && req.Device.MCCMNC != "" {
+			q.Set("yl_rtb_mccmnc", req.Device.MCCMNC)
+		}
+
+		if clickBrowsers := makeClickBrowsers(req); clickBrowsers != "" {
+			q.Set("clickbrowser", clickBrowsers)
+		}
+	}
+
+	if req.User != nil && permitsPersonalization(req, gdpr, consent) {
+		if req.User.Yob != 0 {
+			q.Set("yob", strconv.FormatInt(req.User.Yob, 10))
+		}
+		if req.User.Gender != "" {
+			q.Set("gender", req.User.Gender)
+		}
+	}
+
+	if ids := a.makeIDs(req, gdpr, consent); ids != "" {
+		q.Set("ids", ids)
+	}
+
+	for _, param := range a.extraInfo.OmitQueryParams {
+		q.Del(param)
+	}
+
+	if a.extraInfo.SigningSecret != "" {
+		q.Set("sig", signQuery(q.Encode(), a.extraInfo.SigningSecret))
+	}
+
+	uri.RawQuery = q.Encode()
+
+	return uri.String(), nil
+}
+
+// getContent returns the content query parameter of the request
+func (a *YieldlabAdapter) getContent() string {
+	if a.extraInfo.Content == "" {
+		return contentJSON
+	}
+	return a.extraInfo.Content
+}
+
+// requiresSecure checks if an impression requires secure HTTPS assets
+func requiresSecure(req *openrtb2.BidRequest) bool {
+	for _, imp := range req.Imp {
+		if imp.Secure != nil && *imp.Secure == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// getDeviceType returns the type of the device, falling back to the configured default
+func (a *YieldlabAdapter) getDeviceType(req *openrtb2.BidRequest) openrtb2.DeviceType {
+	if req.Device != nil && req.Device.DeviceType != 0 {
+		return req.Device.DeviceType
+	}
+	return a.extraInfo.DefaultDeviceType
+}
+
+// getContentURL returns the URL of the content the ad is shown with, like the article of a page
+func getContentURL(req *openrtb2.BidRequest) string {
+	if req.Site != nil && req.Site.Content != nil {
+		return req.Site.Content.URL
+	}
+	if req.App != nil && req.App.Content != nil {
+		return req.App.Content.URL
+	}
+	return ""
+}
+
+// getGeo returns the location of the device, falling back to the one of the user
+func getGeo(req *openrtb2.BidRequest) *openrtb2.Geo {
+	if req.Device != nil && req.Device.Geo != nil {
+		return req.Device.Geo
+	}
+	if req.User != nil {
+		return req.User.Geo
+	}
+	return nil
+}
+
+// makeIDs returns the user ids sent to yieldlab. If the buyeruid is empty, the configured fallback
+// is used instead, unless the user opted out of tracking or is protected by COPPA or GDPR. The IFA is sent
+// besides the buyeruid if configured, with the same protections.
+// No ids are sent if GDPR applies without the consent for yieldlab as vendor.
+func (a *YieldlabAdapter) makeIDs(req *openrtb2.BidRequest, gdpr string, consent string) string {
+	if lacksVendorConsent(gdpr, consent) {
+		return ""
+	}
+
+	var ids []string
 	if req.User != nil && req.User.BuyerUID != "" {
-		q.Set("ids", "ylid:"+req.User.BuyerUID)
+		ids = append(ids, "ylid"+idProviderSeparator+req.User.BuyerUID)
+	}
+
+	sendIFA := a.extraInfo.IDsIncludeIFA || (len(ids) == 0 && a.extraInfo.BuyerUIDFallback == buyerUIDFallbackIFA)
+	if sendIFA && permitsIFAID(req, gdpr) {
+		ids = append(ids, "ifa"+idProviderSeparator+req.Device.IFA)
+	}
+
+	return a.joinIDs(ids)
+}
+
+// permitsIFAID checks if the IFA of the device may be sent as user id, which requires that the user didn't opt out
+// of tracking and isn't protected by COPPA or GDPR
+func permitsIFAID(req *openrtb2.BidRequest, gdpr string) bool {
+	if req.Device == nil || req.Device.IFA == "" || limitsAdTracking(req.Device) {
+		return false
+	}
+	if req.Regs != nil && req.Regs.COPPA == 1 {
+		return false
+	}
+	return gdpr != "1"
+}
+
+// joinIDs joins the provider:id entries of the ids parameter with the configured delimiter
+func (a *YieldlabAdapter) joinIDs(ids []string) string {
+	if a.extraInfo.IDsDelimiter == "" {
+		return strings.Join(ids, idsDelimiterDefault)
+	}
+	return strings.Join(ids, a.extraInfo.IDsDelimiter)
+}
+
+// permitsPersonalization checks if yieldlab may use the demographics of the user for personalised ads.
+// Under GDPR, the consent must allow yieldlab to create a profile and select ads based on it.
+func permitsPersonalization(req *openrtb2.BidRequest, gdpr string, consent string) bool {
+	if req.Regs != nil && req.Regs.COPPA == 1 {
+		return false
+	}
+	return gdpr != "1" || hasPersonalizationConsent(consent)
+}
+
+// hasPersonalizationConsent checks if the consent allows yieldlab to personalise ads. For TCF 2 this requires
+// the purposes to create a profile and to select ads based on it, for TCF 1 the personalisation purpose.
+func hasPersonalizationConsent(consent string) bool {
+	parsed, err := vendorconsent.ParseString(consent)
+	if err != nil || !parsed.VendorConsent(yieldlabVendorID) {
+		return false
+	}
+
+	switch parsed.Version() {
+	case 1:
+		return parsed.PurposeAllowed(consentconstants.Personalization)
+	case 2:
+		return parsed.PurposeAllowed(tcf2ConsentConstants.PersonalizationProfile) &&
+			parsed.PurposeAllowed(tcf2ConsentConstants.PersonalizationSelection)
+	default:
+		return false
+	}
+}
+
+// lacksVendorConsent checks if GDPR applies and the consent doesn't allow yieldlab as vendor
+func lacksVendorConsent(gdpr string, consent string) bool {
+	if gdpr != "1" {
+		return false
+	}
+	parsed, err := vendorconsent.ParseString(consent)
+	return err != nil || !parsed.VendorConsent(yieldlabVendorID)
+}
+
+// hasSuppressedIDs checks if the request has identifiers of the user, which aren't sent to yieldlab
+// as GDPR applies without the consent for yieldlab as vendor
+func (a *YieldlabAdapter) hasSuppressedIDs(request *openrtb2.BidRequest) bool {
+	gdpr, consent, err := a.getGDPR(request)
+	if err != nil || !lacksVendorConsent(gdpr, consent) {
+		return false
+	}
+	return (request.User != nil && request.User.BuyerUID != "") || (request.Device != nil && request.Device.IFA != "")
+}
+
+// limitsAdTracking returns whether the user opted out of tracking by the advertising id of the device
+func limitsAdTracking(device *openrtb2.Device) bool {
+	return device.Lmt != nil && *device.Lmt == 1
+}
+
+// signQuery returns the hex encoded HMAC-SHA256 of the canonical, i.e. sorted and encoded, query
+func signQuery(query string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(query))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (a *YieldlabAdapter) getGDPR(request *openrtb2.BidRequest) (string, string, error) {
+	gdpr := ""
+	var extRegs openrtb_ext.ExtRegs
+	if request.Regs != nil {
+		if err := json.Unmarshal(request.Regs.Ext, &extRegs); err != nil {
+			return "", "", fmt.Errorf("failed to parse ExtRegs in Yieldlab GDPR check: %v", err)
+		}
+		if extRegs.GDPR != nil && (*extRegs.GDPR == 0 || *extRegs.GDPR == 1) {
+			gdpr = strconv.Itoa(int(*extRegs.GDPR))
+		}
+	}
+
+	consent := ""
+	if request.User != nil && request.User.Ext != nil {
+		var extUser openrtb_ext.ExtUser
+		if err := json.Unmarshal(request.User.Ext, &extUser); err != nil {
+			return "", "", fmt.Errorf("failed to parse ExtUser in Yieldlab GDPR check: %v", err)
+		}
+		consent = extUser.Consent
+	}
+
+	// GPP takes precedence over an empty legacy consent: if its TCF EU v2 section applies, GDPR applies and the
+	// section is the consent, so identifiers are only sent if it allows yieldlab as vendor
+	if consent == "" {
+		if tcfConsent, applies := getGPPTCFConsent(request); applies {
+			gdpr, consent = "1", tcfConsent
+		}
+	}
+
+	return gdpr, consent, nil
+}
+
+// getGPPTCFConsent returns the TCF EU v2 section of the GPP string and whether regs.ext.gpp_sid lists it as applicable.
+// The consent is empty if the GPP string lacks a valid section.
+func getGPPTCFConsent(req *openrtb2.BidRequest) (string, bool) {
+	if req.Regs == nil || len(req.Regs.Ext) == 0 {
+		return "", false
+	}
+
+	var ext regsExtGPP
+	if err := json.Unmarshal(req.Regs.Ext, &ext); err != nil {
+		return "", false
+	}
+
+	applies := false
+	for _, sid := range ext.GPPSID {
+		if sid == gppSectionTCFEUv2 {
+			applies = true
+			break
+		}
+	}
+	if !applies {
+		return "", false
+	}
+
+	sections, err := parseGPPSections(ext.GPP)
+	if err != nil {
+		return "", true
+	}
+	return sections[gppSectionTCFEUv2], true
+}
+
+// getGPP returns the GPP string of regs.ext.gpp and the applicable sections of regs.ext.gpp_sid separated by commas.
+// It's forwarded besides the legacy GDPR fields.
+func getGPP(req *openrtb2.BidRequest) (string, string) {
+	if req.Regs == nil || len(req.Regs.Ext) == 0 {
+		return "", ""
+	}
+
+	var ext regsExtGPP
+	if err := json.Unmarshal(req.Regs.Ext, &ext); err != nil {
+		return "", ""
+	}
+
+	sids := make([]string, 0, len(ext.GPPSID))
+	for _, sid := range ext.GPPSID {
+		sids = append(sids, strconv.Itoa(sid))
+	}
+	return ext.GPP, strings.Join(sids, ",")
+}
+
+// makeSupplyChain returns the supply chain of source.ext.schain serialized as described in the
+// SupplyChain Object spec: the version and complete flag followed by a node per "!", whose values are
+// separated by commas in the order asi, sid, hp, rid, name, domain and ext.
+func makeSupplyChain(req *openrtb2.BidRequest) string {
+	if req.Source == nil || len(req.Source.Ext) == 0 {
+		return ""
+	}
+	var sourceExt openrtb_ext.SourceExt
+	if err := json.Unmarshal(req.Source.Ext, &sourceExt); err != nil || len(sourceExt.SChain.Nodes) == 0 {
+		return ""
+	}
+
+	schain := sourceExt.SChain
+	var b strings.Builder
+	b.WriteString(escapeSupplyChainValue(schain.Ver))
+	b.WriteString(",")
+	b.WriteString(strconv.Itoa(schain.Complete))
+	for _, node := range schain.Nodes {
+		if node == nil {
+			continue
+		}
+		hp := ""
+		if node.HP != 0 {
+			hp = strconv.Itoa(node.HP)
+		}
+		b.WriteString("!")
+		b.WriteString(strings.Join([]string{
+			escapeSupplyChainValue(node.ASI),
+			escapeSupplyChainValue(node.SID),
+			hp,
+			escapeSupplyChainValue(node.RID),
+			escapeSupplyChainValue(node.Name),
+			escapeSupplyChainValue(node.Domain),
+			escapeSupplyChainValue(string(node.Ext)),
+		}, ","))
+	}
+	return b.String()
+}
+
+// escapeSupplyChainValue escapes a value of the serialized supply chain, including the separators "," and "!"
+func escapeSupplyChainValue(v string) string {
+	return strings.ReplaceAll(url.QueryEscape(v), "!", "%21")
+}
+
+// appendPathSegment appends the segment to the path of the yieldlab URL. It fails if the segment isn't a single
+// segment, e.g. as merged adslots contain a separator or dot segments, as the URL would address another path.
+func appendPathSegment(p string, segment string) (string, error) {
+	joined := strings.TrimSuffix(p, "/") + "/" + segment
+	if segment == "" || strings.Contains(segment, "/") || path.Clean(joined) != joined {
+		return "", &errortypes.BadInput{
+			Message: fmt.Sprintf("invalid yieldlab URL path segment %q", segment),
+		}
+	}
+	return joined, nil
+}
+
+// makeImpTransactionIDs returns the imp.ext.tid of all impressions in the form adslotId:tid, as
+// the impressions are merged into a single request
+func makeImpTransactionIDs(req *openrtb2.BidRequest) string {
+	var tids []string
+	for i := range req.Imp {
+		var ext impExt
+		if err := json.Unmarshal(req.Imp[i].Ext, &ext); err != nil || ext.TID == "" {
+			continue
+		}
+		tids = appendAdslotValues(tids, ext.Bidder.AdslotID, ext.TID)
+	}
+	return strings.Join(tids, adSlotIdSeparator)
+}
+
+// makeImpMetrics returns the imp.metric of all impressions in the form adslotId:type:value
+func makeImpMetrics(req *openrtb2.BidRequest) string {
+	var metrics []string
+	for i := range req.Imp {
+		if len(req.Imp[i].Metric) == 0 {
+			continue
+		}
+		var ext impExt
+		if err := json.Unmarshal(req.Imp[i].Ext, &ext); err != nil {
+			continue
+		}
+		for _, metric := range req.Imp[i].Metric {
+			if metric.Type == "" {
+				continue
+			}
+			metrics = appendAdslotValues(metrics, ext.Bidder.AdslotID,
+				metric.Type+adslotValueSeparator+strconv.FormatFloat(metric.Value, 'f', -1, 64))
+		}
+	}
+	return strings.Join(metrics, adSlotIdSeparator)
+}
+
+// makeVideoPlacements returns the video.placement of all video impressions in the form adslotId:placement
+func makeVideoPlacements(req *openrtb2.BidRequest) string {
+	var placements []string
+	for i := range req.Imp {
+		if req.Imp[i].Video == nil || req.Imp[i].Video.Placement == 0 {
+			continue
+		}
+		var ext impExt
+		if err := json.Unmarshal(req.Imp[i].Ext, &ext); err != nil {
+			continue
+		}
+		placements = appendAdslotValues(placements, ext.Bidder.AdslotID, strconv.Itoa(int(req.Imp[i].Video.Placement)))
+	}
+	return strings.Join(placements, adSlotIdSeparator)
+}
+
+// makeAdUnitCodes returns the imp.ext.prebid.adunitcode of all impressions in the form adslotId:adunitcode,
+// so the reporting of yieldlab can be aligned with the ad units of the publisher
+func makeAdUnitCodes(req *openrtb2.BidRequest) string {
+	var codes []string
+	for i := range req.Imp {
+		var ext impExt
+		if err := json.Unmarshal(req.Imp[i].Ext, &ext); err != nil || ext.Prebid.AdUnitCode == "" {
+			continue
+		}
+		codes = appendAdslotValues(codes, ext.Bidder.AdslotID, ext.Prebid.AdUnitCode)
+	}
+	return strings.Join(codes, adSlotIdSeparator)
+}
+
+// makeClickBrowsers returns the imp.clickbrowser of all impressions whose clicks open the native browser instead of
+// an embedded one in the form adslotId:1, as the creatives yieldlab selects may depend on it
+func makeClickBrowsers(req *openrtb2.BidRequest) string {
+	var clickBrowsers []string
+	for i := range req.Imp {
+		var ext impExt
+		if req.Imp[i].ClickBrowser == 0 || json.Unmarshal(req.Imp[i].Ext, &ext) != nil {
+			continue
+		}
+		clickBrowsers = appendAdslotValues(clickBrowsers, ext.Bidder.AdslotID, strconv.Itoa(int(req.Imp[i].ClickBrowser)))
+	}
+	return strings.Join(clickBrowsers, adSlotIdSeparator)
+}
+
+// makeAuctionEnvironments returns the adslots of the impressions requesting a Protected Audience on-device
+// auction in the form adslotId:1
+func makeAuctionEnvironments(req *openrtb2.BidRequest) string {
+	var environments []string
+	for i := range req.Imp {
+		var ext impExt
+		if err := json.Unmarshal(req.Imp[i].Ext, &ext); err != nil || ext.AE != 1 {
+			continue
+		}
+		environments = appendAdslotValues(environments, ext.Bidder.AdslotID, strconv.Itoa(ext.AE))
+	}
+	return strings.Join(environments, adSlotIdSeparator)
+}
+
+// requestsAuctionEnvironment checks if the impression requests a Protected Audience on-device auction
+func requestsAuctionEnvironment(imp *openrtb2.Imp) bool {
+	var ext impExt
+	return json.Unmarshal(imp.Ext, &ext) == nil && ext.AE == 1
+}
+
+// appendAdslotValues appends the value of an impression in the form adslotId:value, once for each of its adslots
+func appendAdslotValues(values []string, adslotID string, value string) []string {
+	for _, id := range splitAdslotIDs(adslotID) {
+		values = append(values, id+adslotValueSeparator+value)
+	}
+	return values
+}
+
+// splitAdslotIDs splits the adslotId of an impression, which may list several adslots, into the distinct adslots
+func splitAdslotIDs(adslotID string) []string {
+	var ids []string
+	for _, id := range strings.Split(adslotID, adSlotIdSeparator) {
+		id = strings.TrimSpace(id)
+		if id == "" || containsAdslotID(ids, id) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func containsAdslotID(ids []string, adslotID string) bool {
+	for _, id := range ids {
+		if id == adslotID {
+			return true
+		}
+	}
+	return false
+}
+
+// makeFloors returns the imp.bidfloor of all impressions in EUR in the form adslotId:floor.
+// Floors in other currencies are left out if they can't be converted.
+func (a *YieldlabAdapter) makeFloors(req *openrtb2.BidRequest) string {
+	var floors []string
+	for i := range req.Imp {
+		floor := req.Imp[i].BidFloor
+		if floor <= 0 {
+			continue
+		}
+		if cur := req.Imp[i].BidFloorCur; cur != "" && cur != currency.EUR.String() {
+			converted, err := a.convertCurrency(floor, cur, currency.EUR.String())
+			if err != nil {
+				continue
+			}
+			floor = a.roundPrice(converted)
+		}
+		var ext impExt
+		if err := json.Unmarshal(req.Imp[i].Ext, &ext); err != nil {
+			continue
+		}
+		floors = appendAdslotValues(floors, ext.Bidder.AdslotID, strconv.FormatFloat(floor, 'f', -1, 64))
+	}
+	return strings.Join(floors, adSlotIdSeparator)
+}
+
+// makeTargetingValues merges the targeting of the impressions with the configured default targeting,
+// the configured precedence decides which of them wins for keys set by both.
+func (a *YieldlabAdapter) makeTargetingValues(params *openrtb_ext.ExtImpYieldlab) string {
+	values := url.Values{}
+	for k, v := range a.extraInfo.DefaultTargeting {
+		values.Set(k, v)
+	}
+	for k, v := range params.Targeting {
+		if _, ok := a.extraInfo.DefaultTargeting[k]; ok && a.extraInfo.TargetingPrecedence == targetingPrecedenceConfig {
+			continue
+		}
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// MakeRequests makes the request to yieldlab. The reqInfo is optional, without it the currency rates of
+// earlier auctions are kept and the default cache buster is used.
+func (a *YieldlabAdapter) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	if reqInfo == nil {
+		reqInfo = &adapters.ExtraRequestInfo{}
+	}
+
+	if !reqInfo.Deadline.IsZero() && !reqInfo.Deadline.After(a.now()) {
+		return nil, []error{&errortypes.Warning{
+			Message: "skipped the yieldlab request as the deadline of the auction passed",
+		}}
+	}
+
+	if len(request.Imp) > 0 && !a.hasServeableImp(request) {
+		return nil, []error{&errortypes.Warning{
+			Message: "skipped the yieldlab request as none of the impressions has a media type yieldlab serves, which are banner, video and native",
+		}}
+	}
+
+	if a.breaker != nil && !a.breaker.allow() {
+		return nil, []error{&errortypes.Warning{
+			Message: "skipped the yieldlab request as the circuit breaker is open after repeated failures",
+		}}
+	}
+
+	if reqInfo.CurrencyConversions != nil {
+		a.conversionsLock.Lock()
+		a.conversions = reqInfo.CurrencyConversions
+		a.conversionsLock.Unlock()
+	}
+
+	reqData, err := a.planRequest(request, reqInfo)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var errs []error
+	if a.hasSuppressedIDs(request) {
+		errs = append(errs, &errortypes.Warning{
+			Message:     "the user identifiers weren't sent to yieldlab as GDPR applies without the vendor consent for yieldlab",
+			WarningCode: errortypes.InvalidPrivacyConsentWarningCode,
+		})
+	}
+	if keys := findTargetingCollisions(a.parseRequest(request)); len(keys) > 0 {
+		errs = append(errs, &errortypes.Warning{
+			Message: fmt.Sprintf("yieldlab targeting keys with different values per adslot were overwritten by the value of the last adslot: %v", strings.Join(keys, ", ")),
+		})
+	}
+
+	return []*adapters.RequestData{reqData}, errs
+}
+
+// PlanRequest builds the request which would be sent to yieldlab for the given bid request, without
+// executing it. It doesn't change any adapter state, so debugging tools can use it to inspect the URL and headers.
+func (a *YieldlabAdapter) PlanRequest(request *openrtb2.BidRequest) (*adapters.RequestData, error) {
+	return a.planRequest(request, &adapters.ExtraRequestInfo{})
+}
+
+func (a *YieldlabAdapter) planRequest(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) (*adapters.RequestData, error) {
+	if len(request.Imp) == 0 {
+		return nil, fmt.Errorf("invalid request %+v, no Impressions given", request)
+	}
+
+	params := a.parseRequest(request)
+	if err := a.checkAdslotsAllowed(params); err != nil {
+		return nil, err
+	}
+
+	bidURL, err := a.makeEndpointURL(request, a.mergeParams(params), reqInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	headers.Add("Accept", "application/json")
+	if request.Site != nil {
+		headers.Add("Referer", a.makeReferer(request.Site.Page))
+	}
+	if request.Device != nil {
+		headers.Add("User-Agent", request.Device.UA)
+		if a.extraInfo.IPForwarding != ipForwardingNone {
+			headers.Add("X-Forwarded-For", a.makeForwardedIP(request))
+		}
+	}
+	if request.User != nil && !a.hasSuppressedIDs(request) {
+		headers.Add("Cookie", a.makeCookie(request))
+	}
+	if a.extraInfo.TransactionIDHeader != "" && request.Source != nil && request.Source.TID != "" {
+		headers.Add(a.extraInfo.TransactionIDHeader, request.Source.TID)
+	}
+
+	if a.getRequestMethod(request) == http.MethodPost {
+		uri, body := splitQuery(bidURL)
+		headers.Add("Content-Type", "application/x-www-form-urlencoded")
+		return &adapters.RequestData{
+			Method:  http.MethodPost,
+			Uri:     uri,
+			Body:    []byte(body),
+			Headers: headers,
+		}, nil
+	}
+
+	return &adapters.RequestData{
+		Method:  "GET",
+		Uri:     bidURL,
+		Headers: headers,
+	}, nil
+}
+
+// makeCookie returns the cookie header with the yieldlab id of the user and, if configured, the consent string
+func (a *YieldlabAdapter) makeCookie(request *openrtb2.BidRequest) string {
+	cookie := "id=" + request.User.BuyerUID
+	if !a.extraInfo.ConsentCookie {
+		return cookie
+	}
+	if gdpr, consent, err := a.getGDPR(request); err == nil && gdpr == "1" && consent != "" {
+		cookie += "; consent=" + consent
+	}
+	return cookie
+}
+
+// hasServeableImp checks if any impression has a media type yieldlab serves
+func (a *YieldlabAdapter) hasServeableImp(request *openrtb2.BidRequest) bool {
+	for i := range request.Imp {
+		if _, ok := a.getBidType(&request.Imp[i]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getRequestMethod returns the HTTP method configured for the media types of the impressions, POST wins over GET
+func (a *YieldlabAdapter) getRequestMethod(request *openrtb2.BidRequest) string {
+	for i := range request.Imp {
+		bidType, ok := a.getBidType(&request.Imp[i])
+		if ok && a.extraInfo.RequestMethods[bidType] == http.MethodPost {
+			return http.MethodPost
+		}
+	}
+	return http.MethodGet
+}
+
+// splitQuery splits the URL into the URL without query and the query, which is sent as body of POST requests
+func splitQuery(rawURL string) (string, string) {
+	if i := strings.IndexByte(rawURL, '?'); i >= 0 {
+		return rawURL[:i], rawURL[i+1:]
+	}
+	return rawURL, ""
+}
+
+// RetryTimeout returns the configured timeout of the first request to yieldlab, which is retried once if it times out
+func (a *YieldlabAdapter) RetryTimeout(req *adapters.RequestData) time.Duration {
+	return time.Duration(a.extraInfo.RetryTimeoutMs) * time.Millisecond
+}
+
+// makeForwardedIP returns the IP of the device in the configured version, falling back to the other one.
+// It's truncated if configured or if GDPR applies and the user didn't consent to personalised ads.
+func (a *YieldlabAdapter) makeForwardedIP(request *openrtb2.BidRequest) string {
+	device := request.Device
+	if a.extraInfo.IPForwarding == ipForwardingTruncated || a.lacksGDPRConsent(request) {
+		device = privacy.NewScrubber().ScrubDevice(device,
+			privacy.ScrubStrategyDeviceIDNone,
+			privacy.ScrubStrategyIPV4Lowest8,
+			privacy.ScrubStrategyIPV6Lowest32,
+			privacy.ScrubStrategyGeoNone)
+	}
+
+	if device.IP == "" || (a.extraInfo.IPVersion == ipVersion6 && device.IPv6 != "") {
+		return device.IPv6
+	}
+	return device.IP
+}
+
+// lacksGDPRConsent checks if GDPR applies and the consent doesn't allow personalised ads
+func (a *YieldlabAdapter) lacksGDPRConsent(request *openrtb2.BidRequest) bool {
+	gdpr, consent, err := a.getGDPR(request)
+	return err == nil && gdpr == "1" && !hasPersonalizationConsent(consent)
+}
+
+func (a *YieldlabAdapter) makeReferer(page string) string {
+	if !a.extraInfo.StripRefererQuery {
+		return page
+	}
+	if i := strings.IndexAny(page, "?#"); i >= 0 {
+		return page[:i]
+	}
+	return page
+}
+
+// checkAdslotsAllowed returns an error if an adslot isn't part of the configured allowlist
+func (a *YieldlabAdapter) checkAdslotsAllowed(params []*openrtb_ext.ExtImpYieldlab) error {
+	if len(a.extraInfo.AllowedAdslotIDs) == 0 {
+		return nil
+	}
+
+	for _, p := range params {
+		if !isAdslotAllowed(p.AdslotID, a.extraInfo.AllowedAdslotIDs) {
+			return &errortypes.BadInput{
+				Message: fmt.Sprintf("adslotId %v is not allowed", p.AdslotID),
+			}
+		}
+	}
+	return nil
+}
+
+func isAdslotAllowed(adslotID string, allowed []string) bool {
+	for _, id := range allowed {
+		if id == adslotID {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRequest extracts the Yieldlab request information from the request
+func (a *YieldlabAdapter) parseRequest(request *openrtb2.BidRequest) []*openrtb_ext.ExtImpYieldlab {
+	params := make([]*openrtb_ext.ExtImpYieldlab, 0)
+
+	for i := 0; i < len(request.Imp); i++ {
+		bidderExt := new(adapters.ExtImpBidder)
+		if err := json.Unmarshal(request.Imp[i].Ext, bidderExt); err != nil {
+			continue
+		}
+
+		yieldlabExt := new(openrtb_ext.ExtImpYieldlab)
+		if err := json.Unmarshal(bidderExt.Bidder, yieldlabExt); err != nil {
+			continue
+		}
+
+		// an impression listing several adslots requests each of them
+		for _, id := range splitAdslotIDs(yieldlabExt.AdslotID) {
+			adslotExt := *yieldlabExt
+			adslotExt.AdslotID = id
+			params = append(params, &adslotExt)
+		}
+	}
+
+	return params
+}
+
+func (a *YieldlabAdapter) mergeParams(params []*openrtb_ext.ExtImpYieldlab) *openrtb_ext.ExtImpYieldlab {
+	var adSlotIds []string
+	targeting := make(map[string]string)
+
+	for _, p := range params {
+		adSlotIds = append(adSlotIds, p.AdslotID)
+		for k, v := range p.Targeting {
+			targeting[k] = v
+		}
+	}
+
+	return &openrtb_ext.ExtImpYieldlab{
+		AdslotID:  strings.Join(adSlotIds, adSlotIdSeparator),
+		Targeting: targeting,
+	}
+}
+
+// findTargetingCollisions returns the sorted targeting keys set to different values by the adslots,
+// of which mergeParams only keeps the value of the last adslot
+func findTargetingCollisions(params []*openrtb_ext.ExtImpYieldlab) []string {
+	targeting := make(map[string]string)
+	collisions := make(map[string]bool)
+	for _, p := range params {
+		for k, v := range p.Targeting {
+			if prev, ok := targeting[k]; ok && prev != v {
+				collisions[k] = true
+			}
+			targeting[k] = v
+		}
+	}
+
+	keys := make([]string, 0, len(collisions))
+	for k := range collisions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MakeBids make the bids for the bid response without debug information, as it isn't known if the core emits
+// the debug information of the bidder.
+func (a *YieldlabAdapter) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	return a.MakeBidsWithRequestInfo(internalRequest, externalRequest, response, nil)
+}
+
+// MakeBidsWithRequestInfo make the bids for the bid response, adding the debug information only if the core emits
+// the debug information of the bidder, so it doesn't leak into production responses. The outcome is recorded by the
+// circuit breaker, if configured.
+func (a *YieldlabAdapter) MakeBidsWithRequestInfo(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData, reqInfo *adapters.ExtraRequestInfo) (*adapters.BidderResponse, []error) {
+	bidderResponse, errs := a.makeBids(internalRequest, externalRequest, response, reqInfo != nil && reqInfo.Debug)
+	if a.breaker != nil {
+		if hasServerFailure(errs) {
+			a.breaker.recordFailure()
+		} else {
+			a.breaker.recordSuccess()
+		}
+	}
+	return bidderResponse, errs
+}
+
+// HandlesErrorResponse lets the core pass responses with a failure status code to MakeBids if they hold a yieldlab
+// error code, so it's mapped to the error type rather than reported as a BadServerResponse
+func (a *YieldlabAdapter) HandlesErrorResponse(response *adapters.ResponseData) bool {
+	return parseErrorResponse(response) != nil
+}
+
+// RequestFailed records requests which failed without a response, e.g. due to a timeout, with the circuit breaker
+func (a *YieldlabAdapter) RequestFailed(req *adapters.RequestData, err error) {
+	if a.breaker != nil {
+		a.breaker.recordFailure()
+	}
+}
+
+// hasServerFailure checks if yieldlab failed to handle the request
+func hasServerFailure(errs []error) bool {
+	for _, err := range errs {
+		switch err.(type) {
+		case *errortypes.BadServerResponse, *errortypes.BidderTemporarilyDisabled:
+			return true
+		}
+	}
+	return false
+}
+
+func (a *YieldlabAdapter) makeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData, debug bool) (*adapters.BidderResponse, []error) {
+	if err := parseErrorResponse(response); err != nil {
+		return nil, []error{err}
+	}
+
+	if response.StatusCode != 200 {
+		return nil, []error{
+			&errortypes.BadServerResponse{
+				Message: fmt.Sprintf("failed to resolve bids from yieldlab response: Unexpected response code %v", response.StatusCode),
+			},
+		}
+	}
+
+	envelope, trailingData, err := a.parseBids(response.Body)
+	if err != nil {
+		return nil, []error{
+			&errortypes.BadServerResponse{
+				Message: fmt.Sprintf("failed to parse bids response from yieldlab: %v", err),
+			},
+		}
+	}
+
+	bids := envelope.Bids
+	params := a.parseRequest(internalRequest)
+	// the bids are returned in EUR, the currency yieldlab prices them in, prebid server converts them into the auction currency
+	responseCurrency := currency.EUR.String()
+	dsa := getDSARequest(internalRequest)
+	publisherRendersDSA := dsa != nil && dsa.PubRender == 1
+	requiresDSA := a.extraInfo.DropBidsWithoutDSA && isDSARequired(dsa)
+	amp := isAMPRequest(internalRequest)
+
+	bidderResponse := &adapters.BidderResponse{
+		Currency: responseCurrency,
+		Bids:     []*adapters.TypedBid{},
+	}
+
+	var ext seatBidExt
+	if debug {
+		ext.Debug = &seatBidDebug{
+			NoBidAdslots:  findNoBidAdslots(params, bids),
+			RequestBytes:  len(externalRequest.Uri) + len(externalRequest.Body),
+			ResponseBytes: len(response.Body),
+		}
+	}
+
+	var errs []error
+	if trailingData {
+		errs = append(errs, &errortypes.Warning{
+			Message: "ignored unexpected data after the bids of the yieldlab response",
+		})
+	}
+	if a.extraInfo.MaxBids > 0 && len(bids) > a.extraInfo.MaxBids {
+		errs = append(errs, &errortypes.Warning{
+			Message: fmt.Sprintf("dropped %v of %v yieldlab bids as only %v bids are processed per response", len(bids)-a.extraInfo.MaxBids, len(bids), a.extraInfo.MaxBids),
+		})
+		bids = bids[:a.extraInfo.MaxBids]
+	}
+
+	adslotBids := countBidsPerAdslot(bids)
+	var summary advertiserSummary
+	for _, bid := range bids {
+		req := a.findBidReq(uint64(bid.ID), params)
+		imp := findImp(internalRequest, uint64(bid.ID))
+		if req == nil || imp == nil {
+			return nil, []error{
+				fmt.Errorf("failed to find yieldlab request for adslotID %v. This is most likely a programming issue", bid.ID),
+			}
+		}
+
+		bidType, ok := a.getFilledBidType(imp, bid.Format)
+		if !ok {
+			// Yieldlab adapter currently doesn't support Audio ads, nor fills of formats the impression doesn't offer
+			continue
+		}
+
+		if bid.Viewability != nil && *bid.Viewability < a.extraInfo.MinViewability {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("dropped yieldlab bid for adslotID %v as its viewability %v is below the minimum of %v", bid.ID, *bid.Viewability, a.extraInfo.MinViewability),
+			})
+			continue
+		}
+
+		if requiresDSA && bid.DSA == nil {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("dropped yieldlab bid for adslotID %v as it has no DSA transparency information, which the request requires", bid.ID),
+			})
+			continue
+		}
+
+		bidCurrency, bidRate, err := a.getBidCurrency(bid, responseCurrency)
+		if err != nil {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("dropped yieldlab bid for adslotID %v as its currency %v can't be converted to %v: %v", bid.ID, bid.Currency, responseCurrency, err),
+			})
+			continue
+		}
+
+		adsize := selectAdsize(bid.Adsize, imp, bidType)
+		width, height, err := splitSize(adsize)
+		if err != nil {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("dropped yieldlab bid for adslotID %v as its adsize is malformed: %v", bid.ID, err),
+			})
+			continue
+		}
+		if width > maxAdsizeDimension || height > maxAdsizeDimension {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("yieldlab bid for adslotID %v has the implausible adsize %v, the size of the impression is used instead", bid.ID, adsize),
+			})
+			adsize = ""
+		}
+		if adsize == "" {
+			width, height = getImpSize(imp, bidType)
+			if width > 0 && height > 0 {
+				adsize = joinSize(width, height)
+			}
+		} else {
+			if width == 0 || height == 0 {
+				width, height = completeSize(imp, bidType, width, height)
+				if width > 0 && height > 0 {
+					adsize = joinSize(width, height)
+				}
+			}
+			if bidType == openrtb_ext.BidTypeBanner && !isBannerSizeAllowed(imp.Banner, width, height) {
+				errs = append(errs, &errortypes.Warning{
+					Message: fmt.Sprintf("dropped yieldlab bid for adslotID %v as its adsize %v wasn't requested", bid.ID, bid.Adsize),
+				})
+				continue
+			}
+		}
+		if bidType == openrtb_ext.BidTypeBanner && (width == 0 || height == 0) {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("dropped yieldlab bid for adslotID %v as neither the bid nor the impression has a size", bid.ID),
+			})
+			continue
+		}
+
+		responseBid := &openrtb2.Bid{
+			ID:     makeBidID(bid, bidType, adslotBids),
+			Price:  a.makePrice(bid.Price, bidCurrency, responseCurrency, bidRate),
+			ImpID:  imp.ID,
+			CrID:   a.makeCreativeID(req, bid),
+			DealID: makeDealID(uint64(bid.Pid)),
+			CID:    makeCampaignID(bid.Cid),
+			W:      int64(width),
+			H:      int64(height),
+			Exp:    a.getBidExp(imp),
+		}
+
+		ext := bidExt{PriceType: a.extraInfo.PriceType}
+		if bid.Did != 0 {
+			ext.Did = strconv.FormatUint(bid.Did, 10)
+		}
+		if bidCurrency != responseCurrency {
+			ext.OrigBidCPM = a.makeBidPrice(bid.Price)
+			ext.OrigBidCur = bidCurrency
+		}
+		// yieldlab doesn't distinguish the brand from the advertiser, so the advertiser name is the brand name
+		meta := &openrtb_ext.ExtBidPrebidMeta{
+			AdvertiserID:   int(bid.Advertiser.ID),
+			AdvertiserName: bid.Advertiser.Name,
+			BrandName:      bid.Advertiser.Name,
+			MediaType:      string(bidType),
+		}
+		if domain := normalizeAdvertiserDomain(bid.Advertiser.Domain); domain != "" {
+			responseBid.ADomain = []string{domain}
+			meta.AdvertiserDomains = responseBid.ADomain
+		}
+		ext.Prebid = &bidExtPrebid{Meta: meta}
+		if a.extraInfo.VideoPvidTargeting && bidType == openrtb_ext.BidTypeVideo && bid.Pvid != "" {
+			ext.Targeting = map[string]string{targetingKeyPvid: bid.Pvid}
+		}
+		if bid.DSA != nil {
+			ext.DSA = makeDSA(bid.DSA, publisherRendersDSA)
+		}
+		if bidType == openrtb_ext.BidTypeVideo {
+			ext.VideoContext = getVideoContext(imp.Video)
+		}
+		if debug {
+			ext.Debug = &bidDebug{bidResponse: bid, RequestURL: makeRequestURL(externalRequest)}
+		}
+		if responseBid.Ext, err = json.Marshal(ext); err != nil {
+			return nil, []error{err}
+		}
+
+		// the ad is served in the selected size, if yieldlab returned several, or in the size of the impression
+		served := bid
+		if adsize != bid.Adsize {
+			selected := *bid
+			selected.Adsize = adsize
+			served = &selected
+		}
+
+		opts := adSourceOptions{exp: responseBid.Exp, cacheable: amp}
+		switch bidType {
+		case openrtb_ext.BidTypeVideo:
+			responseBid.AdM = a.makeAdSourceURL(internalRequest, req, served, opts)
+		case openrtb_ext.BidTypeNative:
+			if responseBid.AdM, err = makeNativeAdM(imp.Native, bid.Native); err != nil {
+				errs = append(errs, &errortypes.Warning{
+					Message: fmt.Sprintf("dropped yieldlab native bid for adslotID %v: %v", bid.ID, err),
+				})
+				continue
+			}
+		default:
+			opts.renderDSA = bid.DSA != nil && !publisherRendersDSA
+			responseBid.AdM = a.makeBannerAdSource(internalRequest, req, served, opts)
+		}
+
+		typedBid := &adapters.TypedBid{
+			BidType: bidType,
+			Bid:     responseBid,
+		}
+		if a.extraInfo.SeatPerAdvertiser {
+			typedBid.Seat = makeAdvertiserSeat(bid.Advertiser)
+		}
+		bidderResponse.Bids = append(bidderResponse.Bids, typedBid)
+		summary.add(bid.Advertiser)
 	}
 
-	if req.Device != nil {
-		q.Set("yl_rtb_ifa", req.Device.IFA)
-		q.Set("yl_rtb_devicetype", fmt.Sprintf("%v", req.Device.DeviceType))
+	bidderResponse.FledgeAuctionConfigs, errs = makeFledgeAuctionConfigs(internalRequest, envelope.AuctionConfigs, errs)
 
-		if req.Device.ConnectionType != nil {
-			q.Set("yl_rtb_connectiontype", fmt.Sprintf("%v", req.Device.ConnectionType.Val()))
+	if a.extraInfo.AdvertiserSummary {
+		ext.Advertisers = summary
+	}
+	if len(ext.Advertisers) > 0 || ext.Debug != nil {
+		if bidderResponse.Ext, err = json.Marshal(ext); err != nil {
+			return nil, []error{err}
 		}
+	}
 
-		if req.Device.Geo != nil {
-			q.Set("lat", fmt.Sprintf("%v", req.Device.Geo.Lat))
-			q.Set("lon", fmt.Sprintf("%v", req.Device.Geo.Lon))
+	return bidderResponse, errs
+}
+
+// makeFledgeAuctionConfigs maps the auction configs yieldlab returned for adslots to the impressions requesting
+// a Protected Audience on-device auction. Configs of adslots no such impression requests are ignored with a warning.
+func makeFledgeAuctionConfigs(req *openrtb2.BidRequest, configs []*auctionConfig, errs []error) ([]*openrtb_ext.FledgeAuctionConfig, []error) {
+	var fledgeConfigs []*openrtb_ext.FledgeAuctionConfig
+	for _, config := range configs {
+		if config == nil || len(config.Config) == 0 {
+			continue
+		}
+		imp := findImp(req, config.ID)
+		if imp == nil || !requestsAuctionEnvironment(imp) {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("ignored the yieldlab auction config for adslotID %v as no impression requesting an on-device auction has the adslot", config.ID),
+			})
+			continue
 		}
+		fledgeConfigs = append(fledgeConfigs, &openrtb_ext.FledgeAuctionConfig{
+			ImpId:  imp.ID,
+			Config: config.Config,
+		})
 	}
+	return fledgeConfigs, errs
+}
 
-	if req.App != nil {
-		q.Set("pubappname", req.App.Name)
-		q.Set("pubbundlename", req.App.Bundle)
+// findNoBidAdslots returns the requested adslots yieldlab returned no bid for
+func findNoBidAdslots(params []*openrtb_ext.ExtImpYieldlab, bids []*bidResponse) []string {
+	noBids := []string{}
+	for _, p := range params {
+		if containsAdslotID(noBids, p.AdslotID) {
+			continue
+		}
+		hasBid := false
+		for _, bid := range bids {
+			if strconv.FormatUint(uint64(bid.ID), 10) == p.AdslotID {
+				hasBid = true
+				break
+			}
+		}
+		if !hasBid {
+			noBids = append(noBids, p.AdslotID)
+		}
 	}
+	return noBids
+}
 
-	gdpr, consent, err := a.getGDPR(req)
-	if err != nil {
-		return "", err
+// makeNativeAdM builds the OpenRTB native response of the assets requested by the impression from
+// the native assets returned by yieldlab
+func makeNativeAdM(native *openrtb2.Native, assets *nativeAssets) (string, error) {
+	if assets == nil {
+		return "", errors.New("the bid has no native assets")
 	}
-	if gdpr != "" && consent != "" {
-		q.Set("gdpr", gdpr)
-		q.Set("consent", consent)
+
+	var nativeReq nativeRequests.Request
+	if err := json.Unmarshal([]byte(native.Request), &nativeReq); err != nil {
+		return "", fmt.Errorf("failed to parse the native request: %v", err)
 	}
 
-	uri.RawQuery = q.Encode()
+	nativeResp := nativeResponse.Response{
+		Ver:         nativeReq.Ver,
+		Link:        nativeResponse.Link{URL: assets.ClickURL},
+		ImpTrackers: assets.ImpTrackers,
+	}
+	for _, reqAsset := range nativeReq.Assets {
+		respAsset, ok := makeNativeAsset(reqAsset, assets)
+		if !ok {
+			if reqAsset.Required == 1 {
+				return "", fmt.Errorf("the required native asset %v is missing", reqAsset.ID)
+			}
+			continue
+		}
+		nativeResp.Assets = append(nativeResp.Assets, respAsset)
+	}
 
-	return uri.String(), nil
+	adm, err := json.Marshal(nativeResp)
+	if err != nil {
+		return "", err
+	}
+	return string(adm), nil
 }
 
-func (a *YieldlabAdapter) getGDPR(request *openrtb2.BidRequest) (string, string, error) {
-	gdpr := ""
-	var extRegs openrtb_ext.ExtRegs
-	if request.Regs != nil {
-		if err := json.Unmarshal(request.Regs.Ext, &extRegs); err != nil {
-			return "", "", fmt.Errorf("failed to parse ExtRegs in Yieldlab GDPR check: %v", err)
-		}
-		if extRegs.GDPR != nil && (*extRegs.GDPR == 0 || *extRegs.GDPR == 1) {
-			gdpr = strconv.Itoa(int(*extRegs.GDPR))
-		}
-	}
+// makeNativeAsset returns the response asset for the requested asset, if yieldlab returned a matching one
+func makeNativeAsset(reqAsset nativeRequests.Asset, assets *nativeAssets) (nativeResponse.Asset, bool) {
+	id := reqAsset.ID
+	asset := nativeResponse.Asset{ID: &id, Required: reqAsset.Required}
 
-	consent := ""
-	if request.User != nil && request.User.Ext != nil {
-		var extUser openrtb_ext.ExtUser
-		if err := json.Unmarshal(request.User.Ext, &extUser); err != nil {
-			return "", "", fmt.Errorf("failed to parse ExtUser in Yieldlab GDPR check: %v", err)
+	switch {
+	case reqAsset.Title != nil && assets.Title != "":
+		asset.Title = &nativeResponse.Title{Text: truncate(assets.Title, reqAsset.Title.Len)}
+	case reqAsset.Img != nil && assets.Image != nil && (reqAsset.Img.Type == 0 || reqAsset.Img.Type == native1.ImageAssetTypeMain):
+		asset.Img = &nativeResponse.Image{
+			Type: reqAsset.Img.Type,
+			URL:  assets.Image.URL,
+			W:    assets.Image.W,
+			H:    assets.Image.H,
 		}
-		consent = extUser.Consent
+	case reqAsset.Data != nil:
+		for _, data := range assets.Data {
+			if data.Type == reqAsset.Data.Type && data.Value != "" {
+				asset.Data = &nativeResponse.Data{
+					Type:  data.Type,
+					Value: truncate(data.Value, reqAsset.Data.Len),
+				}
+				return asset, true
+			}
+		}
+		return asset, false
+	default:
+		return asset, false
 	}
 
-	return gdpr, consent, nil
+	return asset, true
 }
 
-func (a *YieldlabAdapter) makeTargetingValues(params *openrtb_ext.ExtImpYieldlab) string {
-	values := url.Values{}
-	for k, v := range params.Targeting {
-		values.Set(k, v)
+// truncate shortens the text to the maximum length in characters, if there is one
+func truncate(text string, maxLen int64) string {
+	runes := []rune(text)
+	if maxLen <= 0 || int64(len(runes)) <= maxLen {
+		return text
 	}
-	return values.Encode()
+	return string(runes[:maxLen])
 }
 
-func (a *YieldlabAdapter) MakeRequests(request *openrtb2.BidRequest, _ *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
-	if len(request.Imp) == 0 {
-		return nil, []error{fmt.Errorf("invalid request %+v, no Impressions given", request)}
+// getVideoContext returns whether the video is played in-stream or out-stream, which is unknown without a placement
+func getVideoContext(video *openrtb2.Video) string {
+	switch {
+	case video == nil || video.Placement == 0:
+		return ""
+	case video.Placement == openrtb2.VideoPlacementTypeInStream:
+		return videoContextInstream
+	default:
+		return videoContextOutstream
 	}
+}
 
-	bidURL, err := a.makeEndpointURL(request, a.mergeParams(a.parseRequest(request)))
-	if err != nil {
-		return nil, []error{err}
+// parseErrorResponse returns the error yieldlab reported in the error code header or an error body, if any
+func parseErrorResponse(response *adapters.ResponseData) error {
+	if code := response.Headers.Get(errorCodeHeader); code != "" {
+		return mapErrorCode(code, "")
 	}
 
-	headers := http.Header{}
-	headers.Add("Accept", "application/json")
-	if request.Site != nil {
-		headers.Add("Referer", request.Site.Page)
+	body := bytes.TrimSpace(response.Body)
+	if len(body) == 0 || body[0] != '{' {
+		return nil
 	}
-	if request.Device != nil {
-		headers.Add("User-Agent", request.Device.UA)
-		headers.Add("X-Forwarded-For", request.Device.IP)
+	var errResp errorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error.Code == "" {
+		return nil
 	}
-	if request.User != nil {
-		headers.Add("Cookie", "id="+request.User.BuyerUID)
+	return mapErrorCode(errResp.Error.Code, errResp.Error.Message)
+}
+
+// mapErrorCode maps the yieldlab error codes to the prebid error types, so they are categorized in the metrics
+func mapErrorCode(code, message string) error {
+	msg := fmt.Sprintf("yieldlab returned error %v", code)
+	if message != "" {
+		msg += ": " + message
 	}
 
-	return []*adapters.RequestData{{
-		Method:  "GET",
-		Uri:     bidURL,
-		Headers: headers,
-	}}, nil
+	switch code {
+	case errorCodeRateLimited:
+		return &errortypes.BidderTemporarilyDisabled{Message: msg}
+	case errorCodeInvalidAdslot:
+		return &errortypes.BadInput{Message: msg}
+	case errorCodeNoConsent:
+		return &errortypes.Warning{Message: msg, WarningCode: errortypes.InvalidPrivacyConsentWarningCode}
+	default:
+		return &errortypes.BadServerResponse{Message: msg}
+	}
 }
 
-// parseRequest extracts the Yieldlab request information from the request
-func (a *YieldlabAdapter) parseRequest(request *openrtb2.BidRequest) []*openrtb_ext.ExtImpYieldlab {
-	params := make([]*openrtb_ext.ExtImpYieldlab, 0)
-
-	for i := 0; i < len(request.Imp); i++ {
-		bidderExt := new(adapters.ExtImpBidder)
-		if err := json.Unmarshal(request.Imp[i].Ext, bidderExt); err != nil {
-			continue
-		}
+// parseBids parses the yieldlab response, which is either the array of bids or an envelope object holding them.
+// It fails on unknown fields if strict parsing is configured, and on data after the bids unless it's allowed, in which case
+// it reports whether there was any.
+func (a *YieldlabAdapter) parseBids(body []byte) (*bidResponseEnvelope, bool, error) {
+	envelope := bidResponseEnvelope{Bids: make([]*bidResponse, 0)}
+	var target interface{} = &envelope.Bids
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		target = &envelope
+	}
 
-		yieldlabExt := new(openrtb_ext.ExtImpYieldlab)
-		if err := json.Unmarshal(bidderExt.Bidder, yieldlabExt); err != nil {
-			continue
-		}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if a.extraInfo.StrictResponseParsing {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(target); err != nil {
+		return nil, false, err
+	}
 
-		params = append(params, yieldlabExt)
+	if len(bytes.TrimSpace(body[decoder.InputOffset():])) == 0 {
+		return &envelope, false, nil
 	}
+	if !a.extraInfo.AllowTrailingResponseData {
+		return nil, false, errors.New("unexpected data after the bids")
+	}
+	return &envelope, true, nil
+}
 
-	return params
+// getBidExp returns the expiry of the bid, preferring the hint of the impression over the configured default
+func (a *YieldlabAdapter) getBidExp(imp *openrtb2.Imp) int64 {
+	if imp.Exp > 0 {
+		return imp.Exp
+	}
+	return a.extraInfo.BidTTL
 }
 
-func (a *YieldlabAdapter) mergeParams(params []*openrtb_ext.ExtImpYieldlab) *openrtb_ext.ExtImpYieldlab {
-	var adSlotIds []string
-	targeting := make(map[string]string)
+// getFilledBidType returns the media type of a bid, which is the format yieldlab filled if the response states
+// one and the impression offers it. Otherwise it's the media type of the impression.
+func (a *YieldlabAdapter) getFilledBidType(imp *openrtb2.Imp, format openrtb_ext.BidType) (openrtb_ext.BidType, bool) {
+	switch format {
+	case "":
+		return a.getBidType(imp)
+	case openrtb_ext.BidTypeBanner:
+		return format, imp.Banner != nil
+	case openrtb_ext.BidTypeVideo:
+		return format, imp.Video != nil
+	case openrtb_ext.BidTypeNative:
+		return format, imp.Native != nil
+	default:
+		return "", false
+	}
+}
 
-	for _, p := range params {
-		adSlotIds = append(adSlotIds, p.AdslotID)
-		for k, v := range p.Targeting {
-			targeting[k] = v
+// getBidType returns the media type of the bid for the given impression. If the impression offers both
+// banner and video, the media type configured as preferred wins, which defaults to video.
+func (a *YieldlabAdapter) getBidType(imp *openrtb2.Imp) (openrtb_ext.BidType, bool) {
+	if imp.Video != nil && imp.Banner != nil {
+		if a.extraInfo.PreferMediaType == openrtb_ext.BidTypeBanner {
+			return openrtb_ext.BidTypeBanner, true
 		}
+		return openrtb_ext.BidTypeVideo, true
 	}
 
-	return &openrtb_ext.ExtImpYieldlab{
-		AdslotID:  strings.Join(adSlotIds, adSlotIdSeparator),
-		Targeting: targeting,
+	if imp.Video != nil {
+		return openrtb_ext.BidTypeVideo, true
+	}
+	if imp.Banner != nil {
+		return openrtb_ext.BidTypeBanner, true
+	}
+	if imp.Native != nil {
+		return openrtb_ext.BidTypeNative, true
 	}
+	return "", false
 }
 
-// MakeBids make the bids for the bid response.
-func (a *YieldlabAdapter) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
-	if response.StatusCode != 200 {
-		return nil, []error{
-			&errortypes.BadServerResponse{
-				Message: fmt.Sprintf("failed to resolve bids from yieldlab response: Unexpected response code %v", response.StatusCode),
-			},
-		}
+// getBidCurrency returns the currency of the bid, which is EUR unless the bid states another one, and the rate
+// converting it to the response currency
+func (a *YieldlabAdapter) getBidCurrency(bid *bidResponse, responseCurrency string) (string, float64, error) {
+	bidCurrency := strings.ToUpper(bid.Currency)
+	if bidCurrency == "" || bidCurrency == responseCurrency {
+		return responseCurrency, 1, nil
 	}
 
-	bids := make([]*bidResponse, 0)
-	if err := json.Unmarshal(response.Body, &bids); err != nil {
-		return nil, []error{
-			&errortypes.BadServerResponse{
-				Message: fmt.Sprintf("failed to parse bids response from yieldlab: %v", err),
-			},
-		}
+	bidRate, err := a.convertCurrency(1, bidCurrency, responseCurrency)
+	if err != nil {
+		return "", 0, err
 	}
+	return bidCurrency, bidRate, nil
+}
 
-	params := a.parseRequest(internalRequest)
+// makePrice returns the price of the bid in the response currency, which is rounded as configured if it was converted
+func (a *YieldlabAdapter) makePrice(yieldlabPrice uint, bidCurrency string, responseCurrency string, rate float64) float64 {
+	price := a.makeBidPrice(yieldlabPrice)
+	if bidCurrency == responseCurrency {
+		return price
+	}
+	return a.roundPrice(price * rate)
+}
 
-	bidderResponse := &adapters.BidderResponse{
-		Currency: currency.EUR.String(),
-		Bids:     []*adapters.TypedBid{},
+// makeBidPrice returns the price yieldlab returned in the currency of the bid, dividing it by the configured denominator
+func (a *YieldlabAdapter) makeBidPrice(yieldlabPrice uint) float64 {
+	if a.extraInfo.PriceDenominator == 0 {
+		return float64(yieldlabPrice) / priceDenominatorCents
 	}
+	return float64(yieldlabPrice) / float64(a.extraInfo.PriceDenominator)
+}
 
-	for i, bid := range bids {
-		width, height, err := splitSize(bid.Adsize)
-		if err != nil {
-			return nil, []error{err}
-		}
+// roundPrice rounds the price to cents with the configured rounding mode. Floating point noise is
+// removed beforehand, so prices like 3.0000000000000004 aren't rounded up to the next cent.
+func (a *YieldlabAdapter) roundPrice(price float64) float64 {
+	cents := math.Round(price*100*1e6) / 1e6
+	switch a.extraInfo.RoundingMode {
+	case roundingModeNearest:
+		return math.Round(cents) / 100
+	case roundingModeDown:
+		return math.Floor(cents) / 100
+	case roundingModeUp:
+		return math.Ceil(cents) / 100
+	default:
+		return price
+	}
+}
 
-		req := a.findBidReq(bid.ID, params)
-		if req == nil {
-			return nil, []error{
-				fmt.Errorf("failed to find yieldlab request for adslotID %v. This is most likely a programming issue", bid.ID),
-			}
-		}
+// convertCurrency converts the value with the currency rates of the latest auction, falling back
+// to the configured static rates if the conversion fails
+func (a *YieldlabAdapter) convertCurrency(value float64, from, to string) (float64, error) {
+	a.conversionsLock.RLock()
+	reqInfo := adapters.ExtraRequestInfo{CurrencyConversions: a.conversions}
+	a.conversionsLock.RUnlock()
 
-		var bidType openrtb_ext.BidType
-		responseBid := &openrtb2.Bid{
-			ID:     strconv.FormatUint(bid.ID, 10),
-			Price:  float64(bid.Price) / 100,
-			ImpID:  internalRequest.Imp[i].ID,
-			CrID:   a.makeCreativeID(req, bid),
-			DealID: strconv.FormatUint(bid.Pid, 10),
-			W:      int64(width),
-			H:      int64(height),
-		}
+	converted, err := reqInfo.ConvertCurrency(value, from, to)
+	if err == nil || len(a.extraInfo.FallbackRates) == 0 {
+		return converted, err
+	}
 
-		if internalRequest.Imp[i].Video != nil {
-			bidType = openrtb_ext.BidTypeVideo
-			responseBid.AdM = a.makeAdSourceURL(internalRequest, req, bid)
+	fallbackInfo := adapters.ExtraRequestInfo{CurrencyConversions: pbscurrency.NewRates(time.Time{}, a.extraInfo.FallbackRates)}
+	return fallbackInfo.ConvertCurrency(value, from, to)
+}
 
-		} else if internalRequest.Imp[i].Banner != nil {
-			bidType = openrtb_ext.BidTypeBanner
-			responseBid.AdM = a.makeBannerAdSource(internalRequest, req, bid)
-		} else {
-			// Yieldlab adapter currently doesn't support Audio and Native ads
+// findImp returns the impression requesting the adslot
+func findImp(req *openrtb2.BidRequest, adslotID uint64) *openrtb2.Imp {
+	slotIdStr := strconv.FormatUint(adslotID, 10)
+	for i := range req.Imp {
+		var ext impExt
+		if err := json.Unmarshal(req.Imp[i].Ext, &ext); err != nil {
 			continue
 		}
+		if containsAdslotID(splitAdslotIDs(ext.Bidder.AdslotID), slotIdStr) {
+			return &req.Imp[i]
+		}
+	}
 
-		bidderResponse.Bids = append(bidderResponse.Bids, &adapters.TypedBid{
-			BidType: bidType,
-			Bid:     responseBid,
-		})
+	return nil
+}
+
+// countBidsPerAdslot counts the bids of each adslot, which has several if yieldlab filled multiple formats of it
+func countBidsPerAdslot(bids []*bidResponse) map[uint64]int {
+	counts := make(map[uint64]int, len(bids))
+	for _, bid := range bids {
+		counts[uint64(bid.ID)]++
 	}
+	return counts
+}
 
-	return bidderResponse, nil
+// makeBidID returns the bid ID, which is the adslot ID. The media type is appended if yieldlab filled
+// multiple formats of the adslot, so the IDs of its bids are unique.
+func makeBidID(bid *bidResponse, bidType openrtb_ext.BidType, adslotBids map[uint64]int) string {
+	id := strconv.FormatUint(uint64(bid.ID), 10)
+	if adslotBids[uint64(bid.ID)] > 1 {
+		id += "-" + string(bidType)
+	}
+	return id
 }
 
 func (a *YieldlabAdapter) findBidReq(adslotID uint64, params []*openrtb_ext.ExtImpYieldlab) *openrtb_ext.ExtImpYieldlab {
@@ -268,18 +1808,25 @@ func (a *YieldlabAdapter) findBidReq(adslotID uint64, params []*openrtb_ext.ExtI
 	return nil
 }
 
-func (a *YieldlabAdapter) makeBannerAdSource(req *openrtb2.BidRequest, ext *openrtb_ext.ExtImpYieldlab, res *bidResponse) string {
-	return fmt.Sprintf(adSourceBanner, a.makeAdSourceURL(req, ext, res))
+func (a *YieldlabAdapter) makeBannerAdSource(req *openrtb2.BidRequest, ext *openrtb_ext.ExtImpYieldlab, res *bidResponse, opts adSourceOptions) string {
+	return fmt.Sprintf(adSourceBanner, a.makeAdSourceURL(req, ext, res, opts))
 }
 
-func (a *YieldlabAdapter) makeAdSourceURL(req *openrtb2.BidRequest, ext *openrtb_ext.ExtImpYieldlab, res *bidResponse) string {
+// makeAdSourceURL returns the URL the ad is rendered from
+func (a *YieldlabAdapter) makeAdSourceURL(req *openrtb2.BidRequest, ext *openrtb_ext.ExtImpYieldlab, res *bidResponse, opts adSourceOptions) string {
 	val := url.Values{}
-	val.Set("ts", a.cacheBuster())
+	if !opts.cacheable {
+		val.Set("ts", a.makeCacheBuster())
+	}
+	if opts.exp > 0 && !opts.cacheable {
+		val.Set("exp", strconv.FormatInt(a.now().Unix()+opts.exp, 10))
+	}
+	if opts.renderDSA {
+		val.Set("dsarender", "1")
+	}
 	val.Set("id", ext.ExtId)
-	val.Set("pvid", res.Pvid)
-
-	if req.User != nil {
-		val.Set("ids", "ylid:"+req.User.BuyerUID)
+	if res.Pvid != "" {
+		val.Set("pvid", res.Pvid)
 	}
 
 	gdpr, consent, err := a.getGDPR(req)
@@ -288,25 +1835,242 @@ func (a *YieldlabAdapter) makeAdSourceURL(req *openrtb2.BidRequest, ext *openrtb
 		val.Set("consent", consent)
 	}
 
+	if req.User != nil && !lacksVendorConsent(gdpr, consent) {
+		val.Set("ids", "ylid:"+req.User.BuyerUID)
+	}
+
 	return fmt.Sprintf(adSourceURL, ext.AdslotID, ext.SupplyID, res.Adsize, val.Encode())
 }
 
+// makeRequestURL returns the URL of the request to yieldlab including the query, which is the body of POST requests
+func makeRequestURL(req *adapters.RequestData) string {
+	if req.Method == http.MethodPost && len(req.Body) > 0 {
+		return req.Uri + "?" + string(req.Body)
+	}
+	return req.Uri
+}
+
+// isAMPRequest checks if the auction is an AMP auction, for which the AMP endpoint sets site.ext.amp to 1
+func isAMPRequest(req *openrtb2.BidRequest) bool {
+	if req.Site == nil || len(req.Site.Ext) == 0 {
+		return false
+	}
+
+	var ext openrtb_ext.ExtSite
+	return json.Unmarshal(req.Site.Ext, &ext) == nil && ext.AMP == 1
+}
+
+// getDSARequest returns the DSA request of regs.ext.dsa, it's nil if there's none
+func getDSARequest(req *openrtb2.BidRequest) *dsaRequest {
+	if req.Regs == nil || len(req.Regs.Ext) == 0 {
+		return nil
+	}
+
+	var ext regsExtDSA
+	if err := json.Unmarshal(req.Regs.Ext, &ext); err != nil {
+		return nil
+	}
+	return ext.DSA
+}
+
+// isDSARequired checks if bids have to carry DSA transparency information. A dsarequired of 1 only means
+// it's supported, so bids without it are accepted.
+func isDSARequired(dsa *dsaRequest) bool {
+	return dsa != nil && (dsa.Required == dsaRequired || dsa.Required == dsaRequiredOnlinePlatform)
+}
+
+// makeDSA returns the DSA information of the bid, telling whether the ad or the publisher renders it
+func makeDSA(dsa *dsaResponse, publisherRenders bool) *dsaResponse {
+	adRender := 1
+	if publisherRenders {
+		adRender = 0
+	}
+
+	result := *dsa
+	result.AdRender = &adRender
+	return &result
+}
+
+// normalizeAdvertiserDomain returns the bare lowercase host of the advertiser domain, so it matches the domains of badv
+// even if yieldlab returned it as URL, e.g. "https://WWW.Example.COM/" becomes "example.com"
+func normalizeAdvertiserDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+len("://"):]
+	}
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// makeAdvertiserSeat returns the seat of the bids of the advertiser, which is its name, its domain or its ID in this
+// order of preference. It's empty if yieldlab didn't return the advertiser, so the bid stays in the seat of the bidder.
+func makeAdvertiserSeat(adv advertiser) string {
+	if adv.Name != "" {
+		return adv.Name
+	}
+	if domain := normalizeAdvertiserDomain(adv.Domain); domain != "" {
+		return domain
+	}
+	if adv.ID != 0 {
+		return strconv.FormatInt(adv.ID, 10)
+	}
+	return ""
+}
+
+// makeDealID returns the deal id of the bid, which is empty for a Pid of 0 or the sentinel pidNoDeal as it isn't a deal
+func makeDealID(pid uint64) string {
+	if pid == 0 || pid == pidNoDeal {
+		return ""
+	}
+	return strconv.FormatUint(pid, 10)
+}
+
+// makeCampaignID returns the campaign id of the bid, which is empty if yieldlab didn't return one
+func makeCampaignID(cid uint64) string {
+	if cid == 0 {
+		return ""
+	}
+	return strconv.FormatUint(cid, 10)
+}
+
+// makeRequestCacheBuster returns the cache buster of the request to yieldlab, which is the trace ID if there is one
+// so the request can be correlated with the auction
+func (a *YieldlabAdapter) makeRequestCacheBuster(traceID string) string {
+	if traceID == "" {
+		return a.makeCacheBuster()
+	}
+	return traceID + a.extraInfo.CacheBusterSalt
+}
+
+func (a *YieldlabAdapter) makeCacheBuster() string {
+	return a.cacheBuster() + a.extraInfo.CacheBusterSalt
+}
+
 func (a *YieldlabAdapter) makeCreativeID(req *openrtb_ext.ExtImpYieldlab, bid *bidResponse) string {
-	return fmt.Sprintf(creativeID, req.AdslotID, bid.Pid, a.getWeek())
+	if a.extraInfo.CreativeIDTemplate == "" {
+		return fmt.Sprintf(creativeID, req.AdslotID, bid.Pid, a.getWeek())
+	}
+
+	return strings.NewReplacer(
+		"{adslotId}", req.AdslotID,
+		"{supplyId}", req.SupplyID,
+		"{pid}", strconv.FormatUint(uint64(bid.Pid), 10),
+		"{did}", strconv.FormatUint(bid.Did, 10),
+		"{week}", a.getWeek(),
+	).Replace(a.extraInfo.CreativeIDTemplate)
+}
+
+// getImpSize returns the size of the impression for the given media type, which is used for bids without an adsize
+func getImpSize(imp *openrtb2.Imp, bidType openrtb_ext.BidType) (uint64, uint64) {
+	if bidType == openrtb_ext.BidTypeVideo && imp.Video != nil {
+		return uint64(imp.Video.W), uint64(imp.Video.H)
+	}
+
+	if imp.Banner != nil {
+		for _, format := range imp.Banner.Format {
+			if format.W > 0 && format.H > 0 {
+				return uint64(format.W), uint64(format.H)
+			}
+		}
+		if imp.Banner.W != nil && imp.Banner.H != nil && *imp.Banner.W > 0 && *imp.Banner.H > 0 {
+			return uint64(*imp.Banner.W), uint64(*imp.Banner.H)
+		}
+	}
+
+	return 0, 0
+}
+
+// completeSize fills the missing dimension of a partial adsize like 300x from the first size of the impression
+// matching the given dimension. The size is returned unchanged if there's none.
+func completeSize(imp *openrtb2.Imp, bidType openrtb_ext.BidType, width, height uint64) (uint64, uint64) {
+	var sizes []openrtb2.Format
+	if bidType == openrtb_ext.BidTypeVideo && imp.Video != nil {
+		sizes = append(sizes, openrtb2.Format{W: imp.Video.W, H: imp.Video.H})
+	} else if imp.Banner != nil {
+		sizes = append(sizes, imp.Banner.Format...)
+		if imp.Banner.W != nil && imp.Banner.H != nil {
+			sizes = append(sizes, openrtb2.Format{W: *imp.Banner.W, H: *imp.Banner.H})
+		}
+	}
+
+	for _, size := range sizes {
+		if size.W <= 0 || size.H <= 0 {
+			continue
+		}
+		if (width == 0 || uint64(size.W) == width) && (height == 0 || uint64(size.H) == height) {
+			return uint64(size.W), uint64(size.H)
+		}
+	}
+	return width, height
+}
+
+// isBannerSizeAllowed checks if the size is one of the sizes of the banner. All sizes are allowed if the banner has none.
+func isBannerSizeAllowed(banner *openrtb2.Banner, width, height uint64) bool {
+	if banner == nil {
+		return true
+	}
+
+	hasSizes := false
+	for _, format := range banner.Format {
+		hasSizes = true
+		if uint64(format.W) == width && uint64(format.H) == height {
+			return true
+		}
+	}
+	if banner.W != nil && banner.H != nil {
+		hasSizes = true
+		if uint64(*banner.W) == width && uint64(*banner.H) == height {
+			return true
+		}
+	}
+
+	return !hasSizes
 }
 
+// selectAdsize returns the size of the bid if yieldlab returned several comma separated candidates. It's the first
+// candidate the banner requested, falling back to the first one.
+func selectAdsize(adsize string, imp *openrtb2.Imp, bidType openrtb_ext.BidType) string {
+	candidates := strings.Split(adsize, adsizeCandidateSeparator)
+	if len(candidates) == 1 {
+		return adsize
+	}
+
+	if bidType == openrtb_ext.BidTypeBanner {
+		for _, candidate := range candidates {
+			width, height, err := splitSize(candidate)
+			if err == nil && isBannerSizeAllowed(imp.Banner, width, height) {
+				return candidate
+			}
+		}
+	}
+	return candidates[0]
+}
+
+// joinSize returns the adsize of the dimensions, like 728x90
+func joinSize(width, height uint64) string {
+	return fmt.Sprintf("%d%s%d", width, adsizeSeparator, height)
+}
+
+// splitSize parses an adsize like 728x90. An empty adsize isn't an error, as yieldlab may omit it. The dimensions
+// missing in a partial adsize like 300x are 0.
 func splitSize(size string) (uint64, uint64, error) {
+	if size == "" {
+		return 0, 0, nil
+	}
+
 	sizeParts := strings.Split(size, adsizeSeparator)
 	if len(sizeParts) != 2 {
-		return 0, 0, nil
+		return 0, 0, fmt.Errorf("failed to parse yieldlab adsize: %q", size)
 	}
 
-	width, err := strconv.ParseUint(sizeParts[0], 10, 64)
+	width, err := parseDimension(sizeParts[0])
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to parse yieldlab adsize: %v", err)
 	}
 
-	height, err := strconv.ParseUint(sizeParts[1], 10, 64)
+	height, err := parseDimension(sizeParts[1])
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to parse yieldlab adsize: %v", err)
 	}
@@ -314,3 +2078,10 @@ func splitSize(size string) (uint64, uint64, error) {
 	return width, height, nil
 
 }
+
+func parseDimension(dimension string) (uint64, error) {
+	if dimension == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(dimension, 10, 64)
+}
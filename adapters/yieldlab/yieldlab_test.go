@@ -1,12 +1,23 @@
 package yieldlab
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/adapters/adapterstest"
 	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/currency"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/openrtb_ext"
 )
 
@@ -16,6 +27,10 @@ var testCacheBuster cacheBuster = func() string {
 	return "testing"
 }
 
+var testClock clock = func() time.Time {
+	return time.Unix(1600000000, 0)
+}
+
 var testWeekGenerator weekGenerator = func() string {
 	return "33"
 }
@@ -25,6 +40,7 @@ func newTestYieldlabBidder(endpoint string) *YieldlabAdapter {
 		endpoint:    endpoint,
 		cacheBuster: testCacheBuster,
 		getWeek:     testWeekGenerator,
+		now:         testClock,
 	}
 }
 
@@ -39,6 +55,140 @@ func TestNewYieldlabBidder(t *testing.T) {
 	assert.Equal(t, testURL, bidderYieldlab.endpoint)
 	assert.NotNil(t, bidderYieldlab.cacheBuster)
 	assert.NotNil(t, bidderYieldlab.getWeek)
+	assert.NotNil(t, bidderYieldlab.now)
+}
+
+func TestNewWeekGenerator(t *testing.T) {
+	// Sunday of the 53rd week of 2020 in UTC, but Monday of the 1st week of 2021 in Berlin
+	now := func() time.Time {
+		return time.Date(2021, time.January, 3, 23, 30, 0, 0, time.UTC)
+	}
+
+	assert.Equal(t, "53", newWeekGenerator(now, time.UTC)())
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1", newWeekGenerator(now, berlin)())
+	}
+}
+
+func TestNewYieldlabBidder_extraInfo(t *testing.T) {
+	bidder, buildErr := Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"bid_ttl":300}`,
+	})
+
+	assert.NoError(t, buildErr)
+	bidderYieldlab := bidder.(*YieldlabAdapter)
+	assert.Equal(t, int64(300), bidderYieldlab.extraInfo.BidTTL)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `malformed`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"prefer_media_type":"native"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"buyer_uid_fallback":"imei"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"rounding_mode":"half_even"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"ip_forwarding":"hashed"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"ip_version":"v5"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"targeting_precedence":"request"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"request_methods":{"video":"PUT"}}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"circuit_breaker_threshold":3}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"price_denominator":1000}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"cache_buster_placement":"header"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"min_viewability":70}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"price_type":"both"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"week_timezone":"Europe/Nowhere"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"ids_delimiter":":"}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"additional_user_syncs":[{"url":"https://ad.yieldlab.net/px","type":"script"}]}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"additional_user_syncs":[{"url":"ad.yieldlab.net/px","type":"iframe"}]}`,
+	})
+	assert.Error(t, buildErr)
+
+	_, buildErr = Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:    testURL,
+		UserSyncURL: "https://ad.yieldlab.net/mr?t=2&r=%2Fsetuid",
+	})
+	assert.Error(t, buildErr)
 }
 
 func TestJsonSamples(t *testing.T) {
@@ -74,6 +224,15 @@ func Test_splitSize(t *testing.T) {
 			want1:   0,
 			wantErr: false,
 		},
+		{
+			name: "partial",
+			args: args{
+				size: "300x",
+			},
+			want:    300,
+			want1:   0,
+			wantErr: false,
+		},
 		{
 			name: "invalid",
 			args: args{
@@ -81,7 +240,7 @@ func Test_splitSize(t *testing.T) {
 			},
 			want:    0,
 			want1:   0,
-			wantErr: false,
+			wantErr: true,
 		},
 		{
 			name: "invalid_height",
@@ -108,7 +267,7 @@ func Test_splitSize(t *testing.T) {
 			},
 			want:    0,
 			want1:   0,
-			wantErr: false,
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
@@ -128,6 +287,34 @@ func Test_splitSize(t *testing.T) {
 	}
 }
 
+func TestYieldlabAdapter_MakeRequests_invalidPath(t *testing.T) {
+	for _, adslotID := range []string{"../12345", "12345/67890", "."} {
+		t.Run(adslotID, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			request := newTestBidRequest()
+			request.Imp[0].Ext = json.RawMessage(`{"bidder":{"adslotId":"` + adslotID + `","supplyId":"123456789","adSize":"728x90"}}`)
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			assert.Empty(t, reqData)
+			if assert.Len(t, errs, 1) {
+				assert.IsType(t, &errortypes.BadInput{}, errs[0])
+				assert.Equal(t, fmt.Sprintf("invalid yieldlab URL path segment %q", adslotID), errs[0].Error())
+			}
+		})
+	}
+
+	bidder := newTestYieldlabBidder(testURL)
+	request := newTestBidRequest()
+	request.Imp[0].Ext = json.RawMessage(`{"bidder":{"adslotId":"12345?#","supplyId":"123456789","adSize":"728x90"}}`)
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "/testing/12345?#", uri.Path)
+		assert.Equal(t, "json", uri.Query().Get("content"))
+	}
+}
+
 func TestYieldlabAdapter_makeEndpointURL_invalidEndpoint(t *testing.T) {
 	bidder, buildErr := Builder(openrtb_ext.BidderYieldlab, config.Adapter{
 		Endpoint: "test$:/something§"})
@@ -137,6 +324,2401 @@ func TestYieldlabAdapter_makeEndpointURL_invalidEndpoint(t *testing.T) {
 	}
 
 	bidderYieldlab := bidder.(*YieldlabAdapter)
-	_, err := bidderYieldlab.makeEndpointURL(nil, nil)
+	_, err := bidderYieldlab.makeEndpointURL(nil, nil, &adapters.ExtraRequestInfo{})
+	assert.Error(t, err)
+	assert.IsType(t, &errortypes.FailedToRequestBids{}, err)
+
+	_, errs := bidderYieldlab.MakeRequests(newTestBidRequest(), nil)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.FailedToRequestBids{}, errs[0])
+	}
+}
+
+// newTestBidRequest returns a bid request with a single banner impression for adslot 12345
+func newTestBidRequest() *openrtb2.BidRequest {
+	return &openrtb2.BidRequest{
+		ID: "test-request-id",
+		Imp: []openrtb2.Imp{{
+			ID:     "test-imp-id",
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+			Ext:    json.RawMessage(`{"bidder":{"adslotId":"12345","supplyId":"123456789","adSize":"728x90"}}`),
+		}},
+		Device: &openrtb2.Device{},
+	}
+}
+
+// runTestAuction runs MakeRequests and MakeBids like the core does for the given request and mocked yieldlab response body
+func runTestAuction(t *testing.T, bidder *YieldlabAdapter, request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo, body string) (*adapters.BidderResponse, []error) {
+	t.Helper()
+
+	reqData, errs := bidder.MakeRequests(request, reqInfo)
+	if len(errs) > 0 || len(reqData) != 1 {
+		t.Fatalf("MakeRequests returned unexpected result %v, %v", reqData, errs)
+	}
+
+	return bidder.MakeBidsWithRequestInfo(request, reqData[0], &adapters.ResponseData{
+		StatusCode: 200,
+		Body:       []byte(body),
+	}, reqInfo)
+}
+
+const testResponseBody = `[{"id":12345,"price":201,"advertiser":"yieldlab","adsize":"728x90","pid":1234,"did":5678,"pvid":"40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5"}]`
+
+func TestYieldlabAdapter_MakeBids_bidCurrency(t *testing.T) {
+	rates := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"EUR": {"USD": 1.2},
+	})
+
+	bidder := newTestYieldlabBidder(testURL)
+	request := newTestBidRequest()
+	for _, adslotID := range []string{"67890", "13579"} {
+		request.Imp = append(request.Imp, openrtb2.Imp{
+			ID:     "test-imp-id-" + adslotID,
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+			Ext:    json.RawMessage(`{"bidder":{"adslotId":"` + adslotID + `","supplyId":"123456789","adSize":"728x90"}}`),
+		})
+	}
+
+	resp, errs := runTestAuction(t, bidder, request, &adapters.ExtraRequestInfo{CurrencyConversions: rates}, `[
+		{"id":12345,"price":201,"adsize":"728x90","pid":1234},
+		{"id":67890,"price":240,"adsize":"728x90","pid":1234,"currency":"USD"},
+		{"id":13579,"price":201,"adsize":"728x90","pid":1234,"currency":"GBP"}
+	]`)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Contains(t, errs[0].Error(), "dropped yieldlab bid for adslotID 13579 as its currency GBP can't be converted to EUR")
+	}
+	assert.Equal(t, "EUR", resp.Currency)
+	if assert.Len(t, resp.Bids, 2) {
+		assert.Equal(t, 2.01, resp.Bids[0].Bid.Price)
+		assert.NotContains(t, string(resp.Bids[0].Bid.Ext), "origbidcur")
+
+		assert.InDelta(t, 2.0, resp.Bids[1].Bid.Price, 0.0001)
+		var ext bidExt
+		if assert.NoError(t, json.Unmarshal(resp.Bids[1].Bid.Ext, &ext)) {
+			assert.Equal(t, 2.4, ext.OrigBidCPM)
+			assert.Equal(t, "USD", ext.OrigBidCur)
+		}
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_origBidCPM(t *testing.T) {
+	rates := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"EUR": {"USD": 1.2},
+	})
+
+	bidder := newTestYieldlabBidder(testURL)
+	request := newTestBidRequest()
+
+	resp, errs := runTestAuction(t, bidder, request, &adapters.ExtraRequestInfo{CurrencyConversions: rates}, `[{"id":12345,"price":240,"advertiser":"yieldlab","adsize":"728x90","pid":1234,"did":5678,"currency":"USD"}]`)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"did":"5678","origbidcpm":2.4,"origbidcur":"USD","prebid":{"meta":{"advertiserDomains":["yieldlab"],"mediaType":"banner"}}}`, string(resp.Bids[0].Bid.Ext))
+
+	resp, errs = runTestAuction(t, bidder, request, &adapters.ExtraRequestInfo{CurrencyConversions: rates}, testResponseBody)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"did":"5678","prebid":{"meta":{"advertiserDomains":["yieldlab"],"mediaType":"banner"}}}`, string(resp.Bids[0].Bid.Ext))
+}
+
+func TestYieldlabAdapter_MakeBids_priceDenominator(t *testing.T) {
+	tests := []struct {
+		name        string
+		denominator int
+		wantPrice   float64
+	}{
+		{
+			name:      "default_cents",
+			wantPrice: 2.01,
+		},
+		{
+			name:        "cents",
+			denominator: 100,
+			wantPrice:   2.01,
+		},
+		{
+			name:        "units",
+			denominator: 1,
+			wantPrice:   201,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.PriceDenominator = tt.denominator
+
+			resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.wantPrice, resp.Bids[0].Bid.Price)
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_did(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"did":"5678","prebid":{"meta":{"advertiserDomains":["yieldlab"],"mediaType":"banner"}}}`, string(resp.Bids[0].Bid.Ext))
+
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":1234}]`)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"prebid":{"meta":{"mediaType":"banner"}}}`, string(resp.Bids[0].Bid.Ext))
+}
+
+func TestYieldlabAdapter_MakeBids_fallbackRates(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.FallbackRates = map[string]map[string]float64{"USD": {"EUR": 0.9}}
+
+	request := newTestBidRequest()
+	body := `[{"id":12345,"price":240,"adsize":"728x90","pid":1234,"currency":"USD"}]`
+
+	// the live rates lack USD to EUR
+	rates := currency.NewRates(time.Now(), map[string]map[string]float64{"EUR": {"GBP": 0.9}})
+	resp, errs := runTestAuction(t, bidder, request, &adapters.ExtraRequestInfo{CurrencyConversions: rates}, body)
+	assert.Empty(t, errs)
+	assert.Equal(t, "EUR", resp.Currency)
+	assert.InDelta(t, 2.16, resp.Bids[0].Bid.Price, 0.0001)
+
+	rates = currency.NewRates(time.Now(), map[string]map[string]float64{"USD": {"EUR": 0.8}})
+	resp, errs = runTestAuction(t, bidder, request, &adapters.ExtraRequestInfo{CurrencyConversions: rates}, body)
+	assert.Empty(t, errs)
+	assert.Equal(t, "EUR", resp.Currency)
+	assert.InDelta(t, 1.92, resp.Bids[0].Bid.Price, 0.0001)
+}
+
+func TestYieldlabAdapter_PlanRequest(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Site = &openrtb2.Site{Page: "http://localhost:9090/gdpr.html"}
+	request.Device = &openrtb2.Device{UA: "test-ua", IP: "169.254.13.37", IFA: "hello-ads", DeviceType: 4}
+	request.User = &openrtb2.User{BuyerUID: "34a53e82"}
+
+	reqData, err := bidder.PlanRequest(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", reqData.Method)
+	assert.Equal(t, "https://ad.yieldlab.net/testing/12345?content=json&ids=ylid%3A34a53e82&pvid=true&t=&ts=testing&yl_rtb_devicetype=4&yl_rtb_ifa=hello-ads", reqData.Uri)
+	assert.Equal(t, "http://localhost:9090/gdpr.html", reqData.Headers.Get("Referer"))
+	assert.Equal(t, "test-ua", reqData.Headers.Get("User-Agent"))
+	assert.Equal(t, "169.254.13.37", reqData.Headers.Get("X-Forwarded-For"))
+	assert.Equal(t, "id=34a53e82", reqData.Headers.Get("Cookie"))
+
+	_, err = bidder.PlanRequest(&openrtb2.BidRequest{})
 	assert.Error(t, err)
 }
+
+func TestYieldlabAdapter_MakeBids_preferMediaType(t *testing.T) {
+	tests := []struct {
+		name            string
+		preferMediaType openrtb_ext.BidType
+		wantType        openrtb_ext.BidType
+		wantAdM         string
+	}{
+		{
+			name:     "default_prefers_video",
+			wantType: openrtb_ext.BidTypeVideo,
+			wantAdM:  "https://ad.yieldlab.net/d/12345/123456789/728x90?id=&pvid=40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5&ts=testing",
+		},
+		{
+			name:            "prefer_video",
+			preferMediaType: openrtb_ext.BidTypeVideo,
+			wantType:        openrtb_ext.BidTypeVideo,
+			wantAdM:         "https://ad.yieldlab.net/d/12345/123456789/728x90?id=&pvid=40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5&ts=testing",
+		},
+		{
+			name:            "prefer_banner",
+			preferMediaType: openrtb_ext.BidTypeBanner,
+			wantType:        openrtb_ext.BidTypeBanner,
+			wantAdM:         `<script src="https://ad.yieldlab.net/d/12345/123456789/728x90?id=&pvid=40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5&ts=testing"></script>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.PreferMediaType = tt.preferMediaType
+
+			request := newTestBidRequest()
+			request.Imp[0].Video = &openrtb2.Video{W: 728, H: 90}
+
+			resp, errs := runTestAuction(t, bidder, request, nil, testResponseBody)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.wantType, resp.Bids[0].BidType)
+			assert.Equal(t, tt.wantAdM, resp.Bids[0].Bid.AdM)
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_meta(t *testing.T) {
+	tests := []struct {
+		name      string
+		imp       func(imp *openrtb2.Imp)
+		wantMedia string
+	}{
+		{
+			name:      "banner",
+			wantMedia: "banner",
+		},
+		{
+			name: "video",
+			imp: func(imp *openrtb2.Imp) {
+				imp.Banner = nil
+				imp.Video = &openrtb2.Video{W: 728, H: 90}
+			},
+			wantMedia: "video",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+
+			request := newTestBidRequest()
+			if tt.imp != nil {
+				tt.imp(&request.Imp[0])
+			}
+
+			resp, errs := runTestAuction(t, bidder, request, nil,
+				`[{"id":12345,"price":201,"advertiser":{"name":"Yieldlab","domain":"yieldlab.de"},"adsize":"728x90","pid":1234}]`)
+			assert.Empty(t, errs)
+			var ext bidExt
+			if assert.NoError(t, json.Unmarshal(resp.Bids[0].Bid.Ext, &ext)) && assert.NotNil(t, ext.Prebid) {
+				assert.Equal(t, &openrtb_ext.ExtBidPrebidMeta{
+					AdvertiserDomains: []string{"yieldlab.de"},
+					AdvertiserName:    "Yieldlab",
+					BrandName:         "Yieldlab",
+					MediaType:         tt.wantMedia,
+				}, ext.Prebid.Meta)
+			}
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_videoPvidTargeting(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.VideoPvidTargeting = true
+
+	request := newTestBidRequest()
+	request.Imp[0].Video = &openrtb2.Video{W: 728, H: 90}
+	resp, errs := runTestAuction(t, bidder, request, nil, testResponseBody)
+	assert.Empty(t, errs)
+	var ext bidExt
+	if assert.NoError(t, json.Unmarshal(resp.Bids[0].Bid.Ext, &ext)) {
+		assert.Equal(t, map[string]string{"hb_yl_pvid": "40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5"}, ext.Targeting)
+	}
+
+	// banner bids have no hint
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	ext = bidExt{}
+	if assert.NoError(t, json.Unmarshal(resp.Bids[0].Bid.Ext, &ext)) {
+		assert.Empty(t, ext.Targeting)
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_allowedAdslotIDs(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.AllowedAdslotIDs = []string{"12345"}
+
+	reqData, errs := bidder.MakeRequests(newTestBidRequest(), nil)
+	assert.Empty(t, errs)
+	assert.Len(t, reqData, 1)
+
+	bidder.extraInfo.AllowedAdslotIDs = []string{"54321"}
+
+	reqData, errs = bidder.MakeRequests(newTestBidRequest(), nil)
+	assert.Empty(t, reqData)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.BadInput{}, errs[0])
+		assert.Equal(t, "adslotId 12345 is not allowed", errs[0].Error())
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_delimitedAdslotIDs(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp[0].BidFloor = 1.5
+	request.Imp[0].Ext = json.RawMessage(`{"bidder":{"adslotId":"12345, 67890,12345","supplyId":"123456789","adSize":"728x90"}}`)
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(uri.Path, "/12345,67890"), uri.Path)
+	assert.Equal(t, "12345:1.5,67890:1.5", uri.Query().Get("floors"))
+
+	resp, errs := bidder.MakeBids(request, reqData[0], &adapters.ResponseData{StatusCode: 200, Body: []byte(`[
+		{"id":12345,"price":201,"adsize":"728x90","pid":1234},
+		{"id":67890,"price":150,"adsize":"728x90","pid":5678}
+	]`)})
+	assert.Empty(t, errs)
+	if assert.Len(t, resp.Bids, 2) {
+		assert.Equal(t, "test-imp-id", resp.Bids[0].Bid.ImpID)
+		assert.True(t, strings.HasPrefix(resp.Bids[0].Bid.CrID, "123451234"), resp.Bids[0].Bid.CrID)
+		assert.Contains(t, resp.Bids[0].Bid.AdM, "/d/12345/123456789/728x90")
+		assert.Equal(t, "test-imp-id", resp.Bids[1].Bid.ImpID)
+		assert.True(t, strings.HasPrefix(resp.Bids[1].Bid.CrID, "678905678"), resp.Bids[1].Bid.CrID)
+		assert.Contains(t, resp.Bids[1].Bid.AdM, "/d/67890/123456789/728x90")
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_impExp(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.BidTTL = 300
+
+	request := newTestBidRequest()
+	resp, errs := runTestAuction(t, bidder, request, nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, int64(300), resp.Bids[0].Bid.Exp)
+
+	request.Imp[0].Exp = 60
+	resp, errs = runTestAuction(t, bidder, request, nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, int64(60), resp.Bids[0].Bid.Exp)
+}
+
+func TestYieldlabAdapter_MakeRequests_stripRefererQuery(t *testing.T) {
+	tests := []struct {
+		name              string
+		stripRefererQuery bool
+		page              string
+		wantReferer       string
+	}{
+		{
+			name:        "disabled",
+			page:        "http://localhost:9090/gdpr.html?email=test%40example.com#top",
+			wantReferer: "http://localhost:9090/gdpr.html?email=test%40example.com#top",
+		},
+		{
+			name:              "enabled",
+			stripRefererQuery: true,
+			page:              "http://localhost:9090/gdpr.html?email=test%40example.com#top",
+			wantReferer:       "http://localhost:9090/gdpr.html",
+		},
+		{
+			name:              "enabled_without_query",
+			stripRefererQuery: true,
+			page:              "http://localhost:9090/gdpr.html",
+			wantReferer:       "http://localhost:9090/gdpr.html",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.StripRefererQuery = tt.stripRefererQuery
+
+			request := newTestBidRequest()
+			request.Site = &openrtb2.Site{Page: tt.page}
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.wantReferer, reqData[0].Headers.Get("Referer"))
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_emptyAdsize(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	emptyAdsizeBody := `[{"id":12345,"price":201,"adsize":"","pid":1234,"pvid":"40cb3251"}]`
+
+	request := newTestBidRequest()
+	resp, errs := runTestAuction(t, bidder, request, nil, emptyAdsizeBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, int64(728), resp.Bids[0].Bid.W)
+	assert.Equal(t, int64(90), resp.Bids[0].Bid.H)
+	assert.Contains(t, resp.Bids[0].Bid.AdM, "https://ad.yieldlab.net/d/12345/123456789/728x90?")
+
+	request.Imp[0].Video = &openrtb2.Video{W: 640, H: 480}
+	resp, errs = runTestAuction(t, bidder, request, nil, emptyAdsizeBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, openrtb_ext.BidTypeVideo, resp.Bids[0].BidType)
+	assert.Equal(t, int64(640), resp.Bids[0].Bid.W)
+	assert.Equal(t, int64(480), resp.Bids[0].Bid.H)
+	assert.True(t, strings.HasPrefix(resp.Bids[0].Bid.AdM, "https://ad.yieldlab.net/d/12345/123456789/640x480?"))
+}
+
+func TestYieldlabAdapter_MakeBids_zeroBannerSize(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	emptyAdsizeBody := `[{"id":12345,"price":201,"adsize":"","pid":1234,"pvid":"40cb3251"}]`
+
+	request := newTestBidRequest()
+	request.Imp[0].Banner = &openrtb2.Banner{
+		W:      openrtb2.Int64Ptr(0),
+		H:      openrtb2.Int64Ptr(0),
+		Format: []openrtb2.Format{{W: 0, H: 0}, {W: 300, H: 250}},
+	}
+	resp, errs := runTestAuction(t, bidder, request, nil, emptyAdsizeBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, int64(300), resp.Bids[0].Bid.W)
+	assert.Equal(t, int64(250), resp.Bids[0].Bid.H)
+
+	request.Imp[0].Banner = &openrtb2.Banner{W: openrtb2.Int64Ptr(0), H: openrtb2.Int64Ptr(0)}
+	resp, errs = runTestAuction(t, bidder, request, nil, emptyAdsizeBody)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "dropped yieldlab bid for adslotID 12345 as neither the bid nor the impression has a size", errs[0].Error())
+	}
+	assert.Empty(t, resp.Bids)
+}
+
+func TestYieldlabAdapter_MakeBids_adsizeCandidates(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"300x250,728x90","pid":1234}]`)
+	assert.Empty(t, errs)
+	assert.Equal(t, int64(728), resp.Bids[0].Bid.W)
+	assert.Equal(t, int64(90), resp.Bids[0].Bid.H)
+	assert.Contains(t, resp.Bids[0].Bid.AdM, "/728x90?")
+
+	request := newTestBidRequest()
+	request.Imp[0].Video = &openrtb2.Video{W: 640, H: 480}
+	resp, errs = runTestAuction(t, bidder, request, nil, `[{"id":12345,"price":201,"adsize":"640x480,320x240","pid":1234}]`)
+	assert.Empty(t, errs)
+	assert.Equal(t, int64(640), resp.Bids[0].Bid.W)
+	assert.Equal(t, int64(480), resp.Bids[0].Bid.H)
+}
+
+func TestYieldlabAdapter_MakeBids_implausibleAdsize(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"99999x1","pid":1234}]`)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+	}
+	if assert.Len(t, resp.Bids, 1) {
+		assert.Equal(t, int64(728), resp.Bids[0].Bid.W)
+		assert.Equal(t, int64(90), resp.Bids[0].Bid.H)
+		assert.Contains(t, resp.Bids[0].Bid.AdM, "https://ad.yieldlab.net/d/12345/123456789/728x90?")
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_partialAdsize(t *testing.T) {
+	tests := []struct {
+		name       string
+		adsize     string
+		wantWidth  int64
+		wantHeight int64
+		wantAdsize string
+	}{
+		{
+			name:       "missing_height",
+			adsize:     "300x",
+			wantWidth:  300,
+			wantHeight: 250,
+			wantAdsize: "300x250",
+		},
+		{
+			name:       "missing_width",
+			adsize:     "x90",
+			wantWidth:  728,
+			wantHeight: 90,
+			wantAdsize: "728x90",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+
+			request := newTestBidRequest()
+			request.Imp[0].Banner.Format = []openrtb2.Format{{W: 728, H: 90}, {W: 300, H: 250}}
+
+			resp, errs := runTestAuction(t, bidder, request, nil, `[{"id":12345,"price":201,"adsize":"`+tt.adsize+`","pid":1234}]`)
+			assert.Empty(t, errs)
+			if assert.Len(t, resp.Bids, 1) {
+				assert.Equal(t, tt.wantWidth, resp.Bids[0].Bid.W)
+				assert.Equal(t, tt.wantHeight, resp.Bids[0].Bid.H)
+				assert.Contains(t, resp.Bids[0].Bid.AdM, "https://ad.yieldlab.net/d/12345/123456789/"+tt.wantAdsize+"?")
+			}
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_malformedAdsize(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp = append(request.Imp, openrtb2.Imp{
+		ID:     "test-imp-id-67890",
+		Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+		Ext:    json.RawMessage(`{"bidder":{"adslotId":"67890","supplyId":"123456789","adSize":"728x90"}}`),
+	})
+
+	resp, errs := runTestAuction(t, bidder, request, nil, `[
+		{"id":12345,"price":201,"adsize":"728-90","pid":1234},
+		{"id":67890,"price":201,"adsize":"728x90","pid":1234}
+	]`)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, `dropped yieldlab bid for adslotID 12345 as its adsize is malformed: failed to parse yieldlab adsize: "728-90"`, errs[0].Error())
+	}
+	if assert.Len(t, resp.Bids, 1) {
+		assert.Equal(t, "test-imp-id-67890", resp.Bids[0].Bid.ImpID)
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_signing(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.SigningSecret = "secret"
+
+	request := newTestBidRequest()
+	request.Device = nil
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, "https://ad.yieldlab.net/testing/12345?content=json&pvid=true&sig=81b0dc08c5df927efb84193dd348fbd60ee83c033f4ddcbd8bca67d70c74d33f&t=&ts=testing", reqData[0].Uri)
+}
+
+func TestYieldlabAdapter_MakeBids_disallowedSize(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp = append(request.Imp, openrtb2.Imp{
+		ID:     "test-imp-id-2",
+		Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+		Ext:    json.RawMessage(`{"bidder":{"adslotId":"67890","supplyId":"123456789","adSize":"300x250"}}`),
+	})
+
+	resp, errs := runTestAuction(t, bidder, request, nil, `[
+		{"id":12345,"price":201,"adsize":"728x90","pid":1234},
+		{"id":67890,"price":150,"adsize":"728x90","pid":1234}
+	]`)
+
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "dropped yieldlab bid for adslotID 67890 as its adsize 728x90 wasn't requested", errs[0].Error())
+	}
+	if assert.Len(t, resp.Bids, 1) {
+		assert.Equal(t, "test-imp-id", resp.Bids[0].Bid.ImpID)
+		assert.Equal(t, 2.01, resp.Bids[0].Bid.Price)
+	}
+}
+
+func TestYieldlabAdapter_cacheBusterSalt(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.CacheBusterSalt = "-tenant1"
+
+	request := newTestBidRequest()
+	request.Device = nil
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, "https://ad.yieldlab.net/testing/12345?content=json&pvid=true&t=&ts=testing-tenant1", reqData[0].Uri)
+
+	resp, errs := bidder.MakeBids(request, reqData[0], &adapters.ResponseData{StatusCode: 200, Body: []byte(testResponseBody)})
+	assert.Empty(t, errs)
+	assert.Contains(t, resp.Bids[0].Bid.AdM, "ts=testing-tenant1")
+}
+
+func TestYieldlabAdapter_MakeBids_advertiser(t *testing.T) {
+	tests := []struct {
+		name        string
+		advertiser  string
+		wantADomain []string
+		wantExt     string
+	}{
+		{
+			name:        "single_string",
+			advertiser:  `"yieldlab.de"`,
+			wantADomain: []string{"yieldlab.de"},
+			wantExt:     `{"did":"5678","prebid":{"meta":{"advertiserDomains":["yieldlab.de"],"mediaType":"banner"}}}`,
+		},
+		{
+			name:        "name_and_domain",
+			advertiser:  `{"name":"Yieldlab","domain":"yieldlab.de"}`,
+			wantADomain: []string{"yieldlab.de"},
+			wantExt:     `{"did":"5678","prebid":{"meta":{"advertiserDomains":["yieldlab.de"],"advertiserName":"Yieldlab","brandName":"Yieldlab","mediaType":"banner"}}}`,
+		},
+		{
+			name:       "numeric_string",
+			advertiser: `"4711"`,
+			wantExt:    `{"did":"5678","prebid":{"meta":{"advertiserId":4711,"mediaType":"banner"}}}`,
+		},
+		{
+			name:       "number",
+			advertiser: `4711`,
+			wantExt:    `{"did":"5678","prebid":{"meta":{"advertiserId":4711,"mediaType":"banner"}}}`,
+		},
+		{
+			name:       "numeric_string_above_31_bits",
+			advertiser: `"3000000000"`,
+			wantExt:    `{"did":"5678","prebid":{"meta":{"advertiserId":3000000000,"mediaType":"banner"}}}`,
+		},
+		{
+			name:       "number_above_32_bits",
+			advertiser: `5000000000`,
+			wantExt:    `{"did":"5678","prebid":{"meta":{"advertiserId":5000000000,"mediaType":"banner"}}}`,
+		},
+		{
+			name:       "numeric_string_above_64_bits",
+			advertiser: `"99999999999999999999"`,
+			wantExt:    `{"did":"5678","prebid":{"meta":{"mediaType":"banner"}}}`,
+		},
+		{
+			name:       "number_above_64_bits",
+			advertiser: `99999999999999999999`,
+			wantExt:    `{"did":"5678","prebid":{"meta":{"mediaType":"banner"}}}`,
+		},
+		{
+			name:       "name_only",
+			advertiser: `{"name":"Yieldlab"}`,
+			wantExt:    `{"did":"5678","prebid":{"meta":{"advertiserName":"Yieldlab","brandName":"Yieldlab","mediaType":"banner"}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+
+			resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil,
+				`[{"id":12345,"price":201,"advertiser":`+tt.advertiser+`,"adsize":"728x90","pid":1234,"did":5678}]`)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.wantADomain, resp.Bids[0].Bid.ADomain)
+			assert.JSONEq(t, tt.wantExt, string(resp.Bids[0].Bid.Ext))
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_buyerUIDFallback(t *testing.T) {
+	lmt := int8(1)
+	tests := []struct {
+		name     string
+		fallback string
+		request  func(*openrtb2.BidRequest)
+		wantIDs  string
+	}{
+		{
+			name:     "buyeruid",
+			fallback: buyerUIDFallbackIFA,
+			request: func(r *openrtb2.BidRequest) {
+				r.User = &openrtb2.User{BuyerUID: "34a53e82"}
+			},
+			wantIDs: "ylid:34a53e82",
+		},
+		{
+			name:     "fallback_to_ifa",
+			fallback: buyerUIDFallbackIFA,
+			wantIDs:  "ifa:hello-ads",
+		},
+		{
+			name:    "fallback_disabled",
+			wantIDs: "",
+		},
+		{
+			name:     "lmt",
+			fallback: buyerUIDFallbackIFA,
+			request: func(r *openrtb2.BidRequest) {
+				r.Device.Lmt = &lmt
+			},
+			wantIDs: "",
+		},
+		{
+			name:     "coppa",
+			fallback: buyerUIDFallbackIFA,
+			request: func(r *openrtb2.BidRequest) {
+				r.Regs = &openrtb2.Regs{COPPA: 1, Ext: json.RawMessage(`{}`)}
+			},
+			wantIDs: "",
+		},
+		{
+			name:     "gdpr",
+			fallback: buyerUIDFallbackIFA,
+			request: func(r *openrtb2.BidRequest) {
+				r.Regs = &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)}
+				r.User = &openrtb2.User{Ext: json.RawMessage(`{"consent":"CO5rKAAO5rKAAAHABBENBkCAAPAAAAAAAAYgAoAAAAAAAAAAABAAAUAAAAAAAAAAAAAAAAA"}`)}
+			},
+			wantIDs: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.BuyerUIDFallback = tt.fallback
+
+			request := newTestBidRequest()
+			request.Device.IFA = "hello-ads"
+			if tt.request != nil {
+				tt.request(request)
+			}
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			assert.Empty(t, errs)
+			uri, err := url.Parse(reqData[0].Uri)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantIDs, uri.Query().Get("ids"))
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_noVendorConsent(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	// TCF 2 consent without yieldlab as vendor
+	request := newTestBidRequest()
+	request.Device.IFA = "hello-ads"
+	request.Regs = &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)}
+	request.User = &openrtb2.User{BuyerUID: "34a53e82-0dc3-4815-8b7e-b725ede0361c", Ext: json.RawMessage(`{"consent":"CO5rKAAO5rKAAAHABBENBkCAAPAAAAAAAAYgAoAAAAAAAAAAACAAAUAAAAAAAAAAAAAAAAA"}`)}
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	if assert.Len(t, errs, 1) {
+		warning, ok := errs[0].(*errortypes.Warning)
+		assert.True(t, ok)
+		assert.Equal(t, errortypes.InvalidPrivacyConsentWarningCode, warning.WarningCode)
+		assert.Equal(t, "the user identifiers weren't sent to yieldlab as GDPR applies without the vendor consent for yieldlab", warning.Message)
+	}
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Empty(t, uri.Query().Get("ids"))
+	assert.Empty(t, uri.Query().Get("yl_rtb_ifa"))
+	assert.Empty(t, reqData[0].Headers.Get("Cookie"))
+
+	bids, _ := bidder.MakeBids(request, reqData[0], &adapters.ResponseData{StatusCode: 200, Body: []byte(testResponseBody)})
+	assert.NotContains(t, bids.Bids[0].Bid.AdM, "ids=")
+}
+
+func TestYieldlabAdapter_MakeRequests_deadline(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	reqData, errs := bidder.MakeRequests(newTestBidRequest(), &adapters.ExtraRequestInfo{Deadline: testClock().Add(150 * time.Millisecond)})
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "150", uri.Query().Get("tmax"))
+
+	reqData, errs = bidder.MakeRequests(newTestBidRequest(), &adapters.ExtraRequestInfo{Deadline: testClock()})
+	assert.Empty(t, reqData)
+	assert.Len(t, errs, 1)
+	assert.IsType(t, &errortypes.Warning{}, errs[0])
+}
+
+func TestYieldlabAdapter_MakeRequests_unsupportedMediaTypes(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp[0].Banner = nil
+	request.Imp[0].Audio = &openrtb2.Audio{MIMEs: []string{"audio/mp4"}}
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, reqData)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+	}
+
+	// native impressions are served
+	request.Imp[0].Audio = nil
+	request.Imp[0].Native = &openrtb2.Native{Request: `{"assets":[]}`}
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Len(t, reqData, 1)
+}
+
+func TestYieldlabAdapter_MakeRequests_multipleIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		delimiter string
+		lmt       int8
+		wantIDs   string
+	}{
+		{
+			name:    "default_delimiter",
+			wantIDs: "ylid:34a53e82-0dc3-4815-8b7e-b725ede0361c,ifa:hello-ads",
+		},
+		{
+			name:      "configured_delimiter",
+			delimiter: "|",
+			wantIDs:   "ylid:34a53e82-0dc3-4815-8b7e-b725ede0361c|ifa:hello-ads",
+		},
+		{
+			name:      "limited_ad_tracking",
+			delimiter: "|",
+			lmt:       1,
+			wantIDs:   "ylid:34a53e82-0dc3-4815-8b7e-b725ede0361c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.IDsIncludeIFA = true
+			bidder.extraInfo.IDsDelimiter = tt.delimiter
+
+			request := newTestBidRequest()
+			request.User = &openrtb2.User{BuyerUID: "34a53e82-0dc3-4815-8b7e-b725ede0361c"}
+			request.Device = &openrtb2.Device{IFA: "hello-ads", Lmt: &tt.lmt}
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			assert.Empty(t, errs)
+			uri, err := url.Parse(reqData[0].Uri)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantIDs, uri.Query().Get("ids"))
+			}
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_omitEmptyDeviceParams(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.OmitEmptyDeviceParams = true
+
+	reqData, errs := bidder.MakeRequests(newTestBidRequest(), nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, uri.Query(), "yl_rtb_ifa")
+		assert.NotContains(t, uri.Query(), "yl_rtb_devicetype")
+	}
+
+	request := newTestBidRequest()
+	request.Device = &openrtb2.Device{IFA: "hello-ads", DeviceType: openrtb2.DeviceTypePhone}
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err = url.Parse(reqData[0].Uri)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "hello-ads", uri.Query().Get("yl_rtb_ifa"))
+		assert.Equal(t, "4", uri.Query().Get("yl_rtb_devicetype"))
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_transactionIDHeader(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	request := newTestBidRequest()
+	request.Source = &openrtb2.Source{TID: "b2a7d8f4-0c3e-4e8a-9a55-6d0f1c2e3b4a"}
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Empty(t, reqData[0].Headers.Get("X-Transaction-Id"))
+
+	bidder.extraInfo.TransactionIDHeader = "X-Transaction-Id"
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, "b2a7d8f4-0c3e-4e8a-9a55-6d0f1c2e3b4a", reqData[0].Headers.Get("X-Transaction-Id"))
+
+	request.Source = nil
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	_, ok := reqData[0].Headers["X-Transaction-Id"]
+	assert.False(t, ok)
+}
+
+func TestYieldlabAdapter_MakeRequests_gpp(t *testing.T) {
+	// the TCF EU v2 section of gpp doesn't allow yieldlab as vendor, the consent does
+	const gpp = "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+	const consent = "CO5rKAAO5rKAAAHABBENBkCAAPAAAAAAAAYgAoAAAAAAAAAAABAAAUAAAAAAAAAAAAAAAAA"
+	const consentingGPP = "DBACNY~" + consent + "~1YNN"
+
+	tests := []struct {
+		name        string
+		regsExt     string
+		consent     string
+		wantGDPR    string
+		wantConsent string
+		wantGPP     string
+		wantGPPSID  string
+		wantIDs     string
+		wantWarning bool
+	}{
+		{
+			name:        "legacy_empty_consent",
+			regsExt:     `{"gdpr":1}`,
+			wantWarning: true,
+		},
+		{
+			name:        "consenting_gpp_with_empty_legacy_consent",
+			regsExt:     `{"gdpr":1,"gpp":"` + consentingGPP + `","gpp_sid":[2,6]}`,
+			wantGDPR:    "1",
+			wantConsent: consent,
+			wantGPP:     consentingGPP,
+			wantGPPSID:  "2,6",
+			wantIDs:     "ylid:34a53e82-0dc3-4815-8b7e-b725ede0361c",
+		},
+		{
+			name:        "consenting_gpp_without_legacy_gdpr",
+			regsExt:     `{"gpp":"` + consentingGPP + `","gpp_sid":[2]}`,
+			wantGDPR:    "1",
+			wantConsent: consent,
+			wantGPP:     consentingGPP,
+			wantGPPSID:  "2",
+			wantIDs:     "ylid:34a53e82-0dc3-4815-8b7e-b725ede0361c",
+		},
+		{
+			name:        "not_consenting_gpp_with_empty_legacy_consent",
+			regsExt:     `{"gdpr":1,"gpp":"` + gpp + `","gpp_sid":[2]}`,
+			wantGDPR:    "1",
+			wantConsent: "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+			wantGPP:     gpp,
+			wantGPPSID:  "2",
+			wantWarning: true,
+		},
+		{
+			name:        "consenting_gpp_without_applicable_tcf_section",
+			regsExt:     `{"gdpr":1,"gpp":"` + consentingGPP + `","gpp_sid":[6]}`,
+			wantGPP:     consentingGPP,
+			wantGPPSID:  "6",
+			wantWarning: true,
+		},
+		{
+			name:        "malformed_gpp_with_empty_legacy_consent",
+			regsExt:     `{"gpp":"DBACNY~` + consent + `","gpp_sid":[2]}`,
+			wantGPP:     "DBACNY~" + consent,
+			wantGPPSID:  "2",
+			wantWarning: true,
+		},
+		{
+			name:        "gpp_with_legacy_consent",
+			regsExt:     `{"gdpr":1,"gpp":"` + gpp + `","gpp_sid":[2]}`,
+			consent:     consent,
+			wantGDPR:    "1",
+			wantConsent: consent,
+			wantGPP:     gpp,
+			wantGPPSID:  "2",
+			wantIDs:     "ylid:34a53e82-0dc3-4815-8b7e-b725ede0361c",
+		},
+		{
+			name:    "gpp_without_sections",
+			regsExt: `{"gpp":"` + gpp + `"}`,
+			wantGPP: gpp,
+			wantIDs: "ylid:34a53e82-0dc3-4815-8b7e-b725ede0361c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			request := newTestBidRequest()
+			request.Regs = &openrtb2.Regs{Ext: json.RawMessage(tt.regsExt)}
+			request.User = &openrtb2.User{BuyerUID: "34a53e82-0dc3-4815-8b7e-b725ede0361c"}
+			if tt.consent != "" {
+				request.User.Ext = json.RawMessage(`{"consent":"` + tt.consent + `"}`)
+			}
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			if tt.wantWarning {
+				assert.Len(t, errs, 1)
+			} else {
+				assert.Empty(t, errs)
+			}
+			uri, err := url.Parse(reqData[0].Uri)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantGDPR, uri.Query().Get("gdpr"))
+				assert.Equal(t, tt.wantConsent, uri.Query().Get("consent"))
+				assert.Equal(t, tt.wantGPP, uri.Query().Get("gpp"))
+				assert.Equal(t, tt.wantGPPSID, uri.Query().Get("gpp_sid"))
+				assert.Equal(t, tt.wantIDs, uri.Query().Get("ids"))
+			}
+			if tt.wantWarning {
+				assert.Empty(t, reqData[0].Headers.Get("Cookie"))
+			}
+		})
+	}
+}
+
+func TestParseGPPSections(t *testing.T) {
+	tests := []struct {
+		name         string
+		gpp          string
+		wantSections map[int]string
+		wantErr      bool
+	}{
+		{
+			name:         "single_section",
+			gpp:          "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+			wantSections: map[int]string{2: "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"},
+		},
+		{
+			name:         "several_sections",
+			gpp:          "DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN",
+			wantSections: map[int]string{2: "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", 6: "1YNN"},
+		},
+		{
+			name:         "section_range",
+			gpp:          "DBABzYA~a~b~c",
+			wantSections: map[int]string{6: "a", 7: "b", 8: "c"},
+		},
+		{
+			name:    "missing_section",
+			gpp:     "DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+			wantErr: true,
+		},
+		{
+			name:    "wrong_header_type",
+			gpp:     "BBABMA~a",
+			wantErr: true,
+		},
+		{
+			name:    "truncated_header",
+			gpp:     "DBAB~a",
+			wantErr: true,
+		},
+		{
+			name:    "invalid_character",
+			gpp:     "DB*BMA~a",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, err := parseGPPSections(tt.gpp)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantSections, sections)
+			}
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_consentCookie(t *testing.T) {
+	const consent = "CO5rKAAO5rKAAAHABBENBkCAAPAAAAAAAAYgAoAAAAAAAAAAABAAAUAAAAAAAAAAAAAAAAA"
+
+	tests := []struct {
+		name          string
+		consentCookie bool
+		regs          *openrtb2.Regs
+		wantCookie    string
+	}{
+		{
+			name:       "disabled",
+			regs:       &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+			wantCookie: "id=34a53e82-0dc3-4815-8b7e-b725ede0361c",
+		},
+		{
+			name:          "enabled",
+			consentCookie: true,
+			regs:          &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+			wantCookie:    "id=34a53e82-0dc3-4815-8b7e-b725ede0361c; consent=" + consent,
+		},
+		{
+			name:          "enabled_without_gdpr",
+			consentCookie: true,
+			wantCookie:    "id=34a53e82-0dc3-4815-8b7e-b725ede0361c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.ConsentCookie = tt.consentCookie
+
+			request := newTestBidRequest()
+			request.Regs = tt.regs
+			request.User = &openrtb2.User{BuyerUID: "34a53e82-0dc3-4815-8b7e-b725ede0361c", Ext: json.RawMessage(`{"consent":"` + consent + `"}`)}
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.wantCookie, reqData[0].Headers.Get("Cookie"))
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_cacheBusterPlacement(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement string
+		wantURI   string
+	}{
+		{
+			name:    "default",
+			wantURI: "https://ad.yieldlab.net/testing/12345?content=json&pvid=true&t=&ts=testing&yl_rtb_devicetype=0&yl_rtb_ifa=",
+		},
+		{
+			name:      "query",
+			placement: cacheBusterPlacementQuery,
+			wantURI:   "https://ad.yieldlab.net/testing/12345?content=json&pvid=true&t=&ts=testing&yl_rtb_devicetype=0&yl_rtb_ifa=",
+		},
+		{
+			name:      "path",
+			placement: cacheBusterPlacementPath,
+			wantURI:   "https://ad.yieldlab.net/testing/12345/testing?content=json&pvid=true&t=&yl_rtb_devicetype=0&yl_rtb_ifa=",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.CacheBusterPlacement = tt.placement
+
+			reqData, errs := bidder.MakeRequests(newTestBidRequest(), nil)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.wantURI, reqData[0].Uri)
+		})
+	}
+}
+
+func TestYieldlabAdapter_content(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.Content = "test"
+
+	request := newTestBidRequest()
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, "https://ad.yieldlab.net/testing/12345?content=test&pvid=true&t=&ts=testing&yl_rtb_devicetype=0&yl_rtb_ifa=", reqData[0].Uri)
+
+	resp, errs := bidder.MakeBids(request, reqData[0], &adapters.ResponseData{StatusCode: 200, Body: []byte(testResponseBody)})
+	assert.Empty(t, errs)
+	if assert.Len(t, resp.Bids, 1) {
+		assert.Equal(t, 2.01, resp.Bids[0].Bid.Price)
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_requestMethods(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.RequestMethods = map[openrtb_ext.BidType]string{
+		openrtb_ext.BidTypeBanner: http.MethodGet,
+		openrtb_ext.BidTypeVideo:  http.MethodPost,
+	}
+
+	request := newTestBidRequest()
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, http.MethodGet, reqData[0].Method)
+	assert.Contains(t, reqData[0].Uri, "?content=json")
+	assert.Empty(t, reqData[0].Body)
+
+	request.Imp[0].Banner = nil
+	request.Imp[0].Video = &openrtb2.Video{W: 640, H: 480}
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, http.MethodPost, reqData[0].Method)
+	assert.Equal(t, "https://ad.yieldlab.net/testing/12345", reqData[0].Uri)
+	assert.Equal(t, "application/x-www-form-urlencoded", reqData[0].Headers.Get("Content-Type"))
+	body, err := url.ParseQuery(string(reqData[0].Body))
+	assert.NoError(t, err)
+	assert.Equal(t, "json", body.Get("content"))
+}
+
+func TestYieldlabAdapter_MakeRequests_secure(t *testing.T) {
+	bidder := newTestYieldlabBidder("http://ad.yieldlab.net/testing/")
+
+	request := newTestBidRequest()
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.True(t, strings.HasPrefix(reqData[0].Uri, "http://ad.yieldlab.net/testing/12345?"))
+
+	secure := int8(1)
+	request.Imp[0].Secure = &secure
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.True(t, strings.HasPrefix(reqData[0].Uri, "https://ad.yieldlab.net/testing/12345?"))
+}
+
+func TestYieldlabAdapter_MakeRequests_targetingCollisions(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp[0].Ext = json.RawMessage(`{"bidder":{"adslotId":"12345","supplyId":"123456789","targeting":{"key1":"a","key2":"a","key3":"a"}}}`)
+	request.Imp = append(request.Imp, openrtb2.Imp{
+		ID:     "test-imp-id-67890",
+		Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+		Ext:    json.RawMessage(`{"bidder":{"adslotId":"67890","supplyId":"123456789","targeting":{"key1":"b","key2":"a","key3":"c"}}}`),
+	})
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "yieldlab targeting keys with different values per adslot were overwritten by the value of the last adslot: key1, key3", errs[0].Error())
+	}
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "key1=b&key2=a&key3=c", uri.Query().Get("t"))
+}
+
+func TestYieldlabAdapter_MakeRequests_demographics(t *testing.T) {
+	// TCF 2 consents for yieldlab with and without the purposes 3 and 4 of personalised ads
+	const personalizedConsent = "CO5rKAAO5rKAAAHABBENBkCAAPAAAAAAAAYgAoAAAAAAAAAAABAAAUAAAAAAAAAAAAAAAAA"
+	const basicConsent = "CO5rKAAO5rKAAAHABBENBkCAAMAAAAAAAAYgAoAAAAAAAAAAABAAAUAAAAAAAAAAAAAAAAA"
+
+	tests := []struct {
+		name       string
+		regs       *openrtb2.Regs
+		consent    string
+		wantYob    string
+		wantGender string
+	}{
+		{
+			name:       "no_gdpr",
+			wantYob:    "1984",
+			wantGender: "F",
+		},
+		{
+			name:       "gdpr_consented",
+			regs:       &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+			consent:    personalizedConsent,
+			wantYob:    "1984",
+			wantGender: "F",
+		},
+		{
+			name:    "gdpr_not_consented",
+			regs:    &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+			consent: basicConsent,
+		},
+		{
+			name: "gdpr_without_consent",
+			regs: &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)},
+		},
+		{
+			name: "coppa",
+			regs: &openrtb2.Regs{COPPA: 1, Ext: json.RawMessage(`{}`)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+
+			request := newTestBidRequest()
+			request.Regs = tt.regs
+			request.User = &openrtb2.User{Yob: 1984, Gender: "F", Ext: json.RawMessage(`{"consent":"` + tt.consent + `"}`)}
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			assert.Empty(t, errs)
+			uri, err := url.Parse(reqData[0].Uri)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantYob, uri.Query().Get("yob"))
+			assert.Equal(t, tt.wantGender, uri.Query().Get("gender"))
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_auctionEnvironment(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp[0].Ext = json.RawMessage(`{"ae":1,"bidder":{"adslotId":"12345","supplyId":"123456789"}}`)
+	request.Imp = append(request.Imp, openrtb2.Imp{
+		ID:     "test-imp-id-67890",
+		Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+		Ext:    json.RawMessage(`{"ae":0,"bidder":{"adslotId":"67890","supplyId":"123456789"}}`),
+	})
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345:1", uri.Query().Get("ae"))
+}
+
+func TestYieldlabAdapter_MakeBids_auctionConfigs(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.StrictResponseParsing = true
+
+	request := newTestBidRequest()
+	request.Imp[0].Ext = json.RawMessage(`{"ae":1,"bidder":{"adslotId":"12345","supplyId":"123456789"}}`)
+	request.Imp = append(request.Imp, openrtb2.Imp{
+		ID:     "test-imp-id-67890",
+		Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+		Ext:    json.RawMessage(`{"bidder":{"adslotId":"67890","supplyId":"123456789"}}`),
+	})
+
+	body := `{"bids":` + testResponseBody + `,"auctionConfigs":[` +
+		`{"id":12345,"config":{"seller":"https://ad.yieldlab.net"}},` +
+		`{"id":67890,"config":{"seller":"https://ad.yieldlab.net"}}]}`
+	bidResponse, errs := runTestAuction(t, bidder, request, nil, body)
+
+	assert.Len(t, bidResponse.Bids, 1)
+	assert.Equal(t, []*openrtb_ext.FledgeAuctionConfig{{
+		ImpId:  "test-imp-id",
+		Config: json.RawMessage(`{"seller":"https://ad.yieldlab.net"}`),
+	}}, bidResponse.FledgeAuctionConfigs)
+	// the impression of adslot 67890 doesn't request an on-device auction
+	assert.Equal(t, []error{&errortypes.Warning{
+		Message: "ignored the yieldlab auction config for adslotID 67890 as no impression requesting an on-device auction has the adslot",
+	}}, errs)
+}
+
+func TestYieldlabAdapter_MakeRequests_omitQueryParams(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.OmitQueryParams = []string{"yl_rtb_ifa", "yl_rtb_devicetype", "unknown"}
+	bidder.extraInfo.SigningSecret = "secret"
+
+	request := newTestBidRequest()
+	request.Device.IFA = "hello-ads"
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	query := uri.Query()
+	assert.NotContains(t, query, "yl_rtb_ifa")
+	assert.NotContains(t, query, "yl_rtb_devicetype")
+	assert.Equal(t, "json", query.Get("content"))
+
+	// the signature covers the query without the omitted parameters
+	sig := query.Get("sig")
+	query.Del("sig")
+	assert.Equal(t, signQuery(query.Encode(), "secret"), sig)
+}
+
+func TestYieldlabAdapter_MakeRequests_defaultDeviceType(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Device = nil
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.NotContains(t, uri.Query(), "yl_rtb_devicetype")
+
+	bidder.extraInfo.DefaultDeviceType = openrtb2.DeviceTypePersonalComputer
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err = url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", uri.Query().Get("yl_rtb_devicetype"))
+
+	request.Device = &openrtb2.Device{DeviceType: openrtb2.DeviceTypeMobileTablet}
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err = url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", uri.Query().Get("yl_rtb_devicetype"))
+}
+
+func TestYieldlabAdapter_MakeRequests_lmt(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Device.IFA = "hello-ads"
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello-ads", uri.Query().Get("yl_rtb_ifa"))
+
+	lmt := int8(1)
+	request.Device.Lmt = &lmt
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err = url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Empty(t, uri.Query().Get("yl_rtb_ifa"))
+}
+
+func TestYieldlabAdapter_MakeRequests_targetingPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		precedence string
+		wantT      string
+	}{
+		{
+			name:  "default",
+			wantT: "key1=imp&key2=config",
+		},
+		{
+			name:       "imp",
+			precedence: targetingPrecedenceImp,
+			wantT:      "key1=imp&key2=config",
+		},
+		{
+			name:       "config",
+			precedence: targetingPrecedenceConfig,
+			wantT:      "key1=config&key2=config",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.DefaultTargeting = map[string]string{"key1": "config", "key2": "config"}
+			bidder.extraInfo.TargetingPrecedence = tt.precedence
+
+			request := newTestBidRequest()
+			request.Imp[0].Ext = json.RawMessage(`{"bidder":{"adslotId":"12345","supplyId":"123456789","targeting":{"key1":"imp"}}}`)
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			assert.Empty(t, errs)
+			uri, err := url.Parse(reqData[0].Uri)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantT, uri.Query().Get("t"))
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_geo(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.User = &openrtb2.User{Geo: &openrtb2.Geo{Lat: 52.520008, Lon: 13.404954}}
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "52.520008", uri.Query().Get("lat"))
+	assert.Equal(t, "13.404954", uri.Query().Get("lon"))
+
+	request.Device.Geo = &openrtb2.Geo{Lat: 51.499488, Lon: -0.128953}
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err = url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "51.499488", uri.Query().Get("lat"))
+	assert.Equal(t, "-0.128953", uri.Query().Get("lon"))
+}
+
+func TestYieldlabAdapter_MakeBids_advertiserDomain(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":1234,"advertiser":{"domain":"https://WWW.Example.COM/"}}]`)
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"example.com"}, resp.Bids[0].Bid.ADomain)
+
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":1234,"advertiser":{"domain":" / "}}]`)
+	assert.Empty(t, errs)
+	assert.Empty(t, resp.Bids[0].Bid.ADomain)
+}
+
+func TestYieldlabAdapter_MakeBids_priceType(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.NotContains(t, string(resp.Bids[0].Bid.Ext), "pricetype")
+
+	for _, priceType := range []string{"net", "gross"} {
+		bidder.extraInfo.PriceType = priceType
+		resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+		assert.Empty(t, errs)
+
+		var ext bidExt
+		if assert.NoError(t, json.Unmarshal(resp.Bids[0].Bid.Ext, &ext)) {
+			assert.Equal(t, priceType, ext.PriceType)
+		}
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_zeroPid(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":0}]`)
+	assert.Empty(t, errs)
+	assert.Empty(t, resp.Bids[0].Bid.DealID)
+
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":18446744073709551615}]`)
+	assert.Empty(t, errs)
+	assert.Empty(t, resp.Bids[0].Bid.DealID)
+
+	// a negative or out of range pid is the no deal sentinel and drops neither the bid nor the others of the response
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":-1},{"id":12345,"price":201,"adsize":"728x90","pid":18446744073709551616}]`)
+	assert.Empty(t, errs)
+	if assert.Len(t, resp.Bids, 2) {
+		assert.Empty(t, resp.Bids[0].Bid.DealID)
+		assert.Empty(t, resp.Bids[1].Bid.DealID)
+	}
+
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":"1234"}]`)
+	assert.Empty(t, errs)
+	assert.Equal(t, "1234", resp.Bids[0].Bid.DealID)
+
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, "1234", resp.Bids[0].Bid.DealID)
+}
+
+func TestYieldlabAdapter_MakeBids_strictResponseParsing(t *testing.T) {
+	body := `[{"id":12345,"price":201,"adsize":"728x90","pid":1234,"unexpected":true}]`
+
+	bidder := newTestYieldlabBidder(testURL)
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, body)
+	assert.Empty(t, errs)
+	assert.Len(t, resp.Bids, 1)
+
+	bidder.extraInfo.StrictResponseParsing = true
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, body)
+	assert.Nil(t, resp)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.BadServerResponse{}, errs[0])
+		assert.Contains(t, errs[0].Error(), `unknown field "unexpected"`)
+	}
+
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Len(t, resp.Bids, 1)
+}
+
+func TestYieldlabAdapter_MakeBids_trailingData(t *testing.T) {
+	body := testResponseBody + "\n<!-- served by yieldprobe -->"
+
+	bidder := newTestYieldlabBidder(testURL)
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, body)
+	assert.Nil(t, resp)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.BadServerResponse{}, errs[0])
+		assert.Equal(t, "failed to parse bids response from yieldlab: unexpected data after the bids", errs[0].Error())
+	}
+
+	bidder.extraInfo.AllowTrailingResponseData = true
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, body)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "ignored unexpected data after the bids of the yieldlab response", errs[0].Error())
+	}
+	if assert.Len(t, resp.Bids, 1) {
+		assert.Equal(t, 2.01, resp.Bids[0].Bid.Price)
+	}
+
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody+"\n")
+	assert.Empty(t, errs)
+	assert.Len(t, resp.Bids, 1)
+}
+
+func TestYieldlabAdapter_MakeBids_debug(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"did":"5678","prebid":{"meta":{"advertiserDomains":["yieldlab"],"mediaType":"banner"}}}`, string(resp.Bids[0].Bid.Ext))
+
+	wantExt := func(requestURL string) string {
+		return `{
+			"did":"5678",
+			"prebid":{"meta":{"advertiserDomains":["yieldlab"],"mediaType":"banner"}},
+			"debug":{
+				"id":12345,
+				"price":201,
+				"advertiser":{"domain":"yieldlab"},
+				"adsize":"728x90",
+				"pid":1234,
+				"did":5678,
+				"pvid":"40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5",
+				"requestUrl":"` + requestURL + `"
+			}
+		}`
+	}
+
+	debugInfo := &adapters.ExtraRequestInfo{Debug: true}
+	request := newTestBidRequest()
+	request.Test = 1
+	resp, errs = runTestAuction(t, bidder, request, debugInfo, testResponseBody)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, wantExt("https://ad.yieldlab.net/testing/12345?content=json&pvid=true&t=&testmode=1&ts=testing&yl_rtb_devicetype=0&yl_rtb_ifa="), string(resp.Bids[0].Bid.Ext))
+
+	request = newTestBidRequest()
+	request.Ext = json.RawMessage(`{"prebid":{"debug":true}}`)
+	resp, errs = runTestAuction(t, bidder, request, debugInfo, testResponseBody)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, wantExt("https://ad.yieldlab.net/testing/12345?content=json&pvid=true&t=&ts=testing&yl_rtb_devicetype=0&yl_rtb_ifa="), string(resp.Bids[0].Bid.Ext))
+
+	// the request enabling debug doesn't add the debug information if the core doesn't emit it, e.g. as the account disallows it
+	resp, errs = runTestAuction(t, bidder, request, &adapters.ExtraRequestInfo{}, testResponseBody)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"did":"5678","prebid":{"meta":{"advertiserDomains":["yieldlab"],"mediaType":"banner"}}}`, string(resp.Bids[0].Bid.Ext))
+	assert.Nil(t, resp.Ext)
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	resp, errs = bidder.MakeBids(request, reqData[0], &adapters.ResponseData{StatusCode: 200, Body: []byte(testResponseBody)})
+	assert.Empty(t, errs)
+	assert.NotContains(t, string(resp.Bids[0].Bid.Ext), "requestUrl")
+}
+
+func TestYieldlabAdapter_MakeBids_debugNoBidAdslots(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp = append(request.Imp, openrtb2.Imp{
+		ID:     "test-imp-id-67890",
+		Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+		Ext:    json.RawMessage(`{"bidder":{"adslotId":"67890","supplyId":"123456789","adSize":"728x90"}}`),
+	})
+
+	resp, errs := runTestAuction(t, bidder, request, nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Nil(t, resp.Ext)
+
+	request.Test = 1
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	resp, errs = bidder.MakeBidsWithRequestInfo(request, reqData[0], &adapters.ResponseData{StatusCode: 200, Body: []byte(testResponseBody)}, &adapters.ExtraRequestInfo{Debug: true})
+	assert.Empty(t, errs)
+	wantExt := fmt.Sprintf(`{"debug":{"noBidAdslots":["67890"],"requestBytes":%v,"responseBytes":%v}}`, len(reqData[0].Uri), len(testResponseBody))
+	assert.JSONEq(t, wantExt, string(resp.Ext))
+}
+
+func TestYieldlabAdapter_RetryTimeout(t *testing.T) {
+	bidder, buildErr := Builder(openrtb_ext.BidderYieldlab, config.Adapter{
+		Endpoint:         testURL,
+		ExtraAdapterInfo: `{"retry_timeout_ms":150}`,
+	})
+	assert.NoError(t, buildErr)
+
+	retryBidder, ok := bidder.(adapters.RetryBidder)
+	if assert.True(t, ok) {
+		assert.Equal(t, 150*time.Millisecond, retryBidder.RetryTimeout(&adapters.RequestData{}))
+	}
+
+	assert.Zero(t, newTestYieldlabBidder(testURL).RetryTimeout(&adapters.RequestData{}))
+}
+
+func TestYieldlabAdapter_MakeRequests_floors(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp[0].BidFloor = 1.5
+	request.Imp = append(request.Imp,
+		openrtb2.Imp{
+			ID:          "test-imp-id-2",
+			Banner:      &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+			BidFloor:    0.6,
+			BidFloorCur: "USD",
+			Ext:         json.RawMessage(`{"bidder":{"adslotId":"67890","supplyId":"123456789","adSize":"300x250"}}`),
+		},
+		openrtb2.Imp{
+			ID:     "test-imp-id-3",
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 300, H: 250}}},
+			Ext:    json.RawMessage(`{"bidder":{"adslotId":"13579","supplyId":"123456789","adSize":"300x250"}}`),
+		},
+	)
+
+	reqInfo := &adapters.ExtraRequestInfo{
+		CurrencyConversions: currency.NewRates(time.Now(), map[string]map[string]float64{
+			"USD": {"EUR": 0.5},
+		}),
+	}
+	reqData, errs := bidder.MakeRequests(request, reqInfo)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "12345:1.5,67890:0.3", uri.Query().Get("floors"))
+}
+
+func TestYieldlabAdapter_MakeBids_creativeIDTemplate(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, "12345123433", resp.Bids[0].Bid.CrID)
+
+	bidder.extraInfo.CreativeIDTemplate = "yl-{supplyId}-{adslotId}-{pid}-{did}-{week}-{unknown}"
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, "yl-123456789-12345-1234-5678-33-{unknown}", resp.Bids[0].Bid.CrID)
+}
+
+func TestYieldlabAdapter_MakeBids_nativeAssets(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp[0].Banner = nil
+	request.Imp[0].Native = &openrtb2.Native{
+		Request: `{"ver":"1.2","assets":[{"id":1,"required":1,"title":{"len":8}},{"id":2,"img":{"type":3}}]}`,
+	}
+
+	resp, errs := runTestAuction(t, bidder, request, nil,
+		`[{"id":12345,"price":201,"pid":1234,"native":{"title":"Yieldlab Native","clickUrl":"https://www.yieldlab.de"}}]`)
+	assert.Empty(t, errs)
+	if assert.Len(t, resp.Bids, 1) {
+		assert.Equal(t, openrtb_ext.BidTypeNative, resp.Bids[0].BidType)
+		assert.JSONEq(t, `{"ver":"1.2","assets":[{"id":1,"required":1,"title":{"text":"Yieldlab"}}],"link":{"url":"https://www.yieldlab.de"}}`, resp.Bids[0].Bid.AdM)
+	}
+
+	resp, errs = runTestAuction(t, bidder, request, nil,
+		`[{"id":12345,"price":201,"pid":1234,"native":{"image":{"url":"https://ad.yieldlab.net/native/image.jpg"}}}]`)
+	assert.Empty(t, resp.Bids)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "dropped yieldlab native bid for adslotID 12345: the required native asset 1 is missing", errs[0].Error())
+	}
+
+	resp, errs = runTestAuction(t, bidder, request, nil, `[{"id":12345,"price":201,"pid":1234}]`)
+	assert.Empty(t, resp.Bids)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "dropped yieldlab native bid for adslotID 12345: the bid has no native assets", errs[0].Error())
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_testMode(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.NotContains(t, uri.Query(), "testmode")
+
+	request.Test = 1
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err = url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", uri.Query().Get("testmode"))
+}
+
+func TestYieldlabAdapter_MakeRequests_traceID(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	reqData, errs := bidder.MakeRequests(request, &adapters.ExtraRequestInfo{TraceID: "4bf92f3577b34da6"})
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6", uri.Query().Get("ts"))
+
+	reqData, errs = bidder.MakeRequests(request, &adapters.ExtraRequestInfo{})
+	assert.Empty(t, errs)
+	uri, err = url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "testing", uri.Query().Get("ts"))
+}
+
+func TestYieldlabAdapter_MakeBids_errorCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		headers  http.Header
+		body     string
+		wantType error
+		wantMsg  string
+		wantCode int
+	}{
+		{
+			name:     "rate_limited_status",
+			status:   429,
+			headers:  http.Header{"X-Yieldlab-Error-Code": []string{"rate_limited"}},
+			wantType: &errortypes.BidderTemporarilyDisabled{},
+			wantMsg:  "yieldlab returned error rate_limited",
+		},
+		{
+			name:     "invalid_adslot_status",
+			status:   400,
+			body:     `{"error":{"code":"invalid_adslot","message":"adslot 12345 doesn't exist"}}`,
+			wantType: &errortypes.BadInput{},
+			wantMsg:  "yieldlab returned error invalid_adslot: adslot 12345 doesn't exist",
+		},
+		{
+			name:     "rate_limited_header",
+			status:   204,
+			headers:  http.Header{"X-Yieldlab-Error-Code": []string{"rate_limited"}},
+			wantType: &errortypes.BidderTemporarilyDisabled{},
+			wantMsg:  "yieldlab returned error rate_limited",
+		},
+		{
+			name:     "invalid_adslot_body",
+			status:   200,
+			body:     `{"error":{"code":"invalid_adslot","message":"adslot 12345 doesn't exist"}}`,
+			wantType: &errortypes.BadInput{},
+			wantMsg:  "yieldlab returned error invalid_adslot: adslot 12345 doesn't exist",
+		},
+		{
+			name:     "no_consent_body",
+			status:   200,
+			body:     `{"error":{"code":"no_consent"}}`,
+			wantType: &errortypes.Warning{},
+			wantMsg:  "yieldlab returned error no_consent",
+			wantCode: errortypes.InvalidPrivacyConsentWarningCode,
+		},
+		{
+			name:     "unknown_code",
+			status:   200,
+			body:     `{"error":{"code":"internal"}}`,
+			wantType: &errortypes.BadServerResponse{},
+			wantMsg:  "yieldlab returned error internal",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+
+			response := &adapters.ResponseData{
+				StatusCode: tt.status,
+				Headers:    tt.headers,
+				Body:       []byte(tt.body),
+			}
+			// the core passes responses with a failure status code to MakeBids as they hold an error code
+			assert.True(t, bidder.HandlesErrorResponse(response))
+
+			resp, errs := bidder.MakeBids(newTestBidRequest(), &adapters.RequestData{}, response)
+			assert.Nil(t, resp)
+			if assert.Len(t, errs, 1) {
+				assert.IsType(t, tt.wantType, errs[0])
+				assert.Equal(t, tt.wantMsg, errs[0].Error())
+				if tt.wantCode != 0 {
+					assert.Equal(t, tt.wantCode, errortypes.ReadCode(errs[0]))
+				}
+			}
+		})
+	}
+}
+
+func TestYieldlabAdapter_HandlesErrorResponse(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	// failures without an error code are left to the core
+	assert.False(t, bidder.HandlesErrorResponse(&adapters.ResponseData{StatusCode: 503, Body: []byte("Service Unavailable")}))
+	assert.False(t, bidder.HandlesErrorResponse(&adapters.ResponseData{StatusCode: 500, Body: []byte(`{"message":"internal"}`)}))
+	assert.True(t, bidder.HandlesErrorResponse(&adapters.ResponseData{
+		StatusCode: 429,
+		Headers:    http.Header{"X-Yieldlab-Error-Code": []string{"rate_limited"}},
+	}))
+}
+
+func TestYieldlabAdapter_MakeBids_roundingMode(t *testing.T) {
+	rates := currency.NewRates(time.Now(), map[string]map[string]float64{
+		"USD": {"EUR": 0.8},
+	})
+
+	tests := []struct {
+		roundingMode string
+		wantPrice    float64
+		wantFloors   string
+	}{
+		{roundingMode: "", wantPrice: 1.608, wantFloors: "12345:0.984"},
+		{roundingMode: roundingModeNearest, wantPrice: 1.61, wantFloors: "12345:0.98"},
+		{roundingMode: roundingModeDown, wantPrice: 1.6, wantFloors: "12345:0.98"},
+		{roundingMode: roundingModeUp, wantPrice: 1.61, wantFloors: "12345:0.99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.roundingMode, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.RoundingMode = tt.roundingMode
+
+			request := newTestBidRequest()
+			request.Imp[0].BidFloor = 1.23
+			request.Imp[0].BidFloorCur = "USD"
+
+			reqInfo := &adapters.ExtraRequestInfo{CurrencyConversions: rates}
+			reqData, errs := bidder.MakeRequests(request, reqInfo)
+			assert.Empty(t, errs)
+			uri, err := url.Parse(reqData[0].Uri)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFloors, uri.Query().Get("floors"))
+
+			resp, errs := runTestAuction(t, bidder, request, reqInfo, `[{"id":12345,"price":201,"adsize":"728x90","pid":1234,"currency":"USD"}]`)
+			assert.Empty(t, errs)
+			assert.Equal(t, "EUR", resp.Currency)
+			assert.InDelta(t, tt.wantPrice, resp.Bids[0].Bid.Price, 0.0000001)
+		})
+	}
+
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.RoundingMode = roundingModeUp
+	assert.Equal(t, 3.0, bidder.roundPrice(2.5*1.2))
+}
+
+func TestYieldlabAdapter_MakeRequests_supplyChain(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Source = &openrtb2.Source{Ext: json.RawMessage(`{"schain":{"ver":"1.0","complete":1,"nodes":[
+		{"asi":"indirectseller.com","sid":"00001","hp":1},
+		{"asi":"exchange,one.com","sid":"1234!abcd","hp":1,"rid":"bid-request-1","name":"publisher","domain":"publisher.com","ext":{"k":"v"}}
+	]}}`)}
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0,1!indirectseller.com,00001,1,,,,!exchange%2Cone.com,1234%21abcd,1,bid-request-1,publisher,publisher.com,%7B%22k%22%3A%22v%22%7D", uri.Query().Get("schain"))
+
+	request.Source = &openrtb2.Source{TID: "tid"}
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err = url.Parse(reqData[0].Uri)
+	assert.NoError(t, err)
+	assert.NotContains(t, uri.Query(), "schain")
+}
+
+func TestYieldlabAdapter_MakeRequests_nilReqInfo(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp[0].BidFloor = 1.5
+	request.Imp[0].BidFloorCur = "USD"
+
+	assert.NotPanics(t, func() {
+		reqData, errs := bidder.MakeRequests(request, nil)
+		assert.Empty(t, errs)
+		uri, err := url.Parse(reqData[0].Uri)
+		assert.NoError(t, err)
+		assert.Equal(t, "testing", uri.Query().Get("ts"))
+		assert.NotContains(t, uri.Query(), "floors")
+
+		resp, errs := bidder.MakeBids(request, reqData[0], &adapters.ResponseData{StatusCode: 200, Body: []byte(testResponseBody)})
+		assert.Empty(t, errs)
+		assert.Equal(t, "EUR", resp.Currency)
+		assert.Equal(t, 2.01, resp.Bids[0].Bid.Price)
+	})
+}
+
+func TestYieldlabAdapter_MakeRequests_ipForwarding(t *testing.T) {
+	tests := []struct {
+		name         string
+		ipForwarding string
+		ipVersion    string
+		ip           string
+		ipv6         string
+		wantHeader   []string
+	}{
+		{
+			name:       "default",
+			ip:         "169.254.13.37",
+			ipv6:       "2001:db8:85a3::8a2e:370:7334",
+			wantHeader: []string{"169.254.13.37"},
+		},
+		{
+			name:       "full_v6",
+			ipVersion:  ipVersion6,
+			ip:         "169.254.13.37",
+			ipv6:       "2001:db8:85a3::8a2e:370:7334",
+			wantHeader: []string{"2001:db8:85a3::8a2e:370:7334"},
+		},
+		{
+			name:       "full_v4_fallback_to_v6",
+			ipVersion:  ipVersion4,
+			ipv6:       "2001:db8:85a3::8a2e:370:7334",
+			wantHeader: []string{"2001:db8:85a3::8a2e:370:7334"},
+		},
+		{
+			name:       "full_v6_fallback_to_v4",
+			ipVersion:  ipVersion6,
+			ip:         "169.254.13.37",
+			wantHeader: []string{"169.254.13.37"},
+		},
+		{
+			name:         "truncated_v4",
+			ipForwarding: ipForwardingTruncated,
+			ip:           "169.254.13.37",
+			ipv6:         "2001:db8:85a3::8a2e:370:7334",
+			wantHeader:   []string{"169.254.13.0"},
+		},
+		{
+			name:         "truncated_v6",
+			ipForwarding: ipForwardingTruncated,
+			ipVersion:    ipVersion6,
+			ip:           "169.254.13.37",
+			ipv6:         "2001:db8:85a3::8a2e:370:7334",
+			wantHeader:   []string{"2001:db8:85a3::8a2e:0:0"},
+		},
+		{
+			name:         "none",
+			ipForwarding: ipForwardingNone,
+			ip:           "169.254.13.37",
+			ipv6:         "2001:db8:85a3::8a2e:370:7334",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.IPForwarding = tt.ipForwarding
+			bidder.extraInfo.IPVersion = tt.ipVersion
+
+			request := newTestBidRequest()
+			request.Device.IP = tt.ip
+			request.Device.IPv6 = tt.ipv6
+
+			reqData, errs := bidder.MakeRequests(request, nil)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.wantHeader, reqData[0].Headers.Values("X-Forwarded-For"))
+			assert.Equal(t, tt.ip, request.Device.IP)
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeRequests_ipForwardingGDPR(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Device.IP = "169.254.13.37"
+	request.Regs = &openrtb2.Regs{Ext: json.RawMessage(`{"gdpr":1}`)}
+
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, "169.254.13.0", reqData[0].Headers.Get("X-Forwarded-For"))
+
+	// TCF 2 consent for yieldlab with the purposes 3 and 4 of personalised ads
+	request.User = &openrtb2.User{Ext: json.RawMessage(`{"consent":"CO5rKAAO5rKAAAHABBENBkCAAPAAAAAAAAYgAoAAAAAAAAAAABAAAUAAAAAAAAAAAAAAAAA"}`)}
+	reqData, errs = bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, "169.254.13.37", reqData[0].Headers.Get("X-Forwarded-For"))
+}
+
+func TestYieldlabAdapter_circuitBreaker(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.breaker = newCircuitBreaker(2, time.Minute, 30*time.Second, func() time.Time { return now })
+
+	makeRequests := func() []error {
+		_, errs := bidder.MakeRequests(newTestBidRequest(), nil)
+		return errs
+	}
+	failedResponse := &adapters.ResponseData{StatusCode: 200, Body: []byte(`{"error":{"code":"internal"}}`)}
+	okResponse := &adapters.ResponseData{StatusCode: 200, Body: []byte(testResponseBody)}
+	reqData := &adapters.RequestData{Method: http.MethodGet, Uri: testURL}
+
+	// failures outside of the window don't open the breaker
+	assert.Empty(t, makeRequests())
+	bidder.RequestFailed(nil, errors.New("timeout"))
+	now = now.Add(2 * time.Minute)
+	assert.Empty(t, makeRequests())
+	bidder.MakeBids(newTestBidRequest(), reqData, failedResponse)
+	assert.Empty(t, makeRequests())
+
+	// it opens at the threshold
+	bidder.RequestFailed(nil, errors.New("timeout"))
+	errs := makeRequests()
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "skipped the yieldlab request as the circuit breaker is open after repeated failures", errs[0].Error())
+	}
+
+	// it half-opens after the cooldown to send a single probe, which reopens it on failure
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, makeRequests())
+	assert.Len(t, makeRequests(), 1)
+	bidder.MakeBids(newTestBidRequest(), reqData, failedResponse)
+	assert.Len(t, makeRequests(), 1)
+
+	// a successful probe closes it
+	now = now.Add(30 * time.Second)
+	assert.Empty(t, makeRequests())
+	bidder.MakeBids(newTestBidRequest(), reqData, okResponse)
+	assert.Empty(t, makeRequests())
+	assert.Empty(t, makeRequests())
+}
+
+func TestYieldlabAdapter_MakeBids_pvid(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	wantPvid := "pvid=40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5"
+
+	request := newTestBidRequest()
+	resp, errs := runTestAuction(t, bidder, request, nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, openrtb_ext.BidTypeBanner, resp.Bids[0].BidType)
+	assert.Contains(t, resp.Bids[0].Bid.AdM, wantPvid)
+
+	// the adm of video bids is the URL the VAST is loaded from
+	request.Imp[0].Video = &openrtb2.Video{W: 640, H: 480}
+	resp, errs = runTestAuction(t, bidder, request, nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, openrtb_ext.BidTypeVideo, resp.Bids[0].BidType)
+	assert.Contains(t, resp.Bids[0].Bid.AdM, wantPvid)
+}
+
+func TestYieldlabAdapter_MakeBids_mixedIDTypes(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	for _, adslotID := range []string{"67890", "13579"} {
+		request.Imp = append(request.Imp, openrtb2.Imp{
+			ID:     "test-imp-id-" + adslotID,
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+			Ext:    json.RawMessage(`{"bidder":{"adslotId":"` + adslotID + `","supplyId":"123456789","adSize":"728x90"}}`),
+		})
+	}
+
+	resp, errs := runTestAuction(t, bidder, request, nil, `[
+		{"id":12345,"price":201,"adsize":"728x90","pid":1234},
+		{"id":"67890","price":150,"adsize":"728x90","pid":1234},
+		{"id":13579,"price":100,"adsize":"728x90","pid":1234}
+	]`)
+	assert.Empty(t, errs)
+	if assert.Len(t, resp.Bids, 3) {
+		assert.Equal(t, "12345", resp.Bids[0].Bid.ID)
+		assert.Equal(t, "test-imp-id", resp.Bids[0].Bid.ImpID)
+		assert.Equal(t, "67890", resp.Bids[1].Bid.ID)
+		assert.Equal(t, "test-imp-id-67890", resp.Bids[1].Bid.ImpID)
+		assert.Equal(t, 1.5, resp.Bids[1].Bid.Price)
+		assert.Equal(t, "13579", resp.Bids[2].Bid.ID)
+		assert.Equal(t, "test-imp-id-13579", resp.Bids[2].Bid.ImpID)
+	}
+
+	_, errs = runTestAuction(t, bidder, request, nil, `[{"id":"abc","price":201,"adsize":"728x90","pid":1234}]`)
+	assert.Len(t, errs, 1)
+}
+
+func TestYieldlabAdapter_MakeBids_emptyPvid(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":1234,"pvid":""}]`)
+	assert.Empty(t, errs)
+	assert.Equal(t, `<script src="https://ad.yieldlab.net/d/12345/123456789/728x90?id=&ts=testing"></script>`, resp.Bids[0].Bid.AdM)
+}
+
+func TestYieldlabAdapter_MakeBids_maxBids(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.MaxBids = 2
+
+	request := newTestBidRequest()
+	for _, adslotID := range []string{"67890", "13579"} {
+		request.Imp = append(request.Imp, openrtb2.Imp{
+			ID:     "test-imp-id-" + adslotID,
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+			Ext:    json.RawMessage(`{"bidder":{"adslotId":"` + adslotID + `","supplyId":"123456789","adSize":"728x90"}}`),
+		})
+	}
+
+	resp, errs := runTestAuction(t, bidder, request, nil, `[
+		{"id":12345,"price":201,"adsize":"728x90","pid":1234},
+		{"id":67890,"price":150,"adsize":"728x90","pid":1234},
+		{"id":13579,"price":100,"adsize":"728x90","pid":1234}
+	]`)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "dropped 1 of 3 yieldlab bids as only 2 bids are processed per response", errs[0].Error())
+	}
+	if assert.Len(t, resp.Bids, 2) {
+		assert.Equal(t, "12345", resp.Bids[0].Bid.ID)
+		assert.Equal(t, "67890", resp.Bids[1].Bid.ID)
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_dsaPubRender(t *testing.T) {
+	body := `[{"id":12345,"price":201,"adsize":"728x90","pid":1234,"pvid":"40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5",
+		"dsa":{"behalf":"yieldlab","paid":"yieldlab","transparency":[{"domain":"yieldlab.com","dsaparams":[1,2]}]}}]`
+
+	tests := []struct {
+		name         string
+		pubRender    int
+		wantAdM      string
+		wantAdRender int
+	}{
+		{
+			name:         "ad_renders",
+			pubRender:    0,
+			wantAdM:      `<script src="https://ad.yieldlab.net/d/12345/123456789/728x90?dsarender=1&id=&pvid=40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5&ts=testing"></script>`,
+			wantAdRender: 1,
+		},
+		{
+			name:         "publisher_renders",
+			pubRender:    1,
+			wantAdM:      `<script src="https://ad.yieldlab.net/d/12345/123456789/728x90?id=&pvid=40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5&ts=testing"></script>`,
+			wantAdRender: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+
+			request := newTestBidRequest()
+			request.Regs = &openrtb2.Regs{Ext: json.RawMessage(fmt.Sprintf(`{"dsa":{"dsarequired":2,"pubrender":%d}}`, tt.pubRender))}
+
+			resp, errs := runTestAuction(t, bidder, request, nil, body)
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.wantAdM, resp.Bids[0].Bid.AdM)
+
+			var ext bidExt
+			assert.NoError(t, json.Unmarshal(resp.Bids[0].Bid.Ext, &ext))
+			if assert.NotNil(t, ext.DSA) && assert.NotNil(t, ext.DSA.AdRender) {
+				assert.Equal(t, tt.wantAdRender, *ext.DSA.AdRender)
+			}
+			assert.Equal(t, "yieldlab", ext.DSA.Behalf)
+		})
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_dsaRequired(t *testing.T) {
+	tests := []struct {
+		name     string
+		drop     bool
+		regsExt  string
+		wantBids int
+	}{
+		{
+			name:     "required",
+			drop:     true,
+			regsExt:  `{"dsa":{"dsarequired":2}}`,
+			wantBids: 0,
+		},
+		{
+			name:     "required_without_drop",
+			regsExt:  `{"dsa":{"dsarequired":2}}`,
+			wantBids: 1,
+		},
+		{
+			name:     "supported",
+			drop:     true,
+			regsExt:  `{"dsa":{"dsarequired":1}}`,
+			wantBids: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidder := newTestYieldlabBidder(testURL)
+			bidder.extraInfo.DropBidsWithoutDSA = tt.drop
+
+			request := newTestBidRequest()
+			request.Regs = &openrtb2.Regs{Ext: json.RawMessage(tt.regsExt)}
+
+			resp, errs := runTestAuction(t, bidder, request, nil, testResponseBody)
+			assert.Len(t, resp.Bids, tt.wantBids)
+			if tt.wantBids == 0 && assert.Len(t, errs, 1) {
+				assert.IsType(t, &errortypes.Warning{}, errs[0])
+			} else if tt.wantBids > 0 {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+
+	// bids with DSA information aren't dropped
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.DropBidsWithoutDSA = true
+	request := newTestBidRequest()
+	request.Regs = &openrtb2.Regs{Ext: json.RawMessage(`{"dsa":{"dsarequired":3}}`)}
+	resp, errs := runTestAuction(t, bidder, request, nil, `[{"id":12345,"price":201,"adsize":"728x90","pid":1234,"dsa":{"behalf":"Advertiser","paid":"Advertiser"}}]`)
+	assert.Empty(t, errs)
+	assert.Len(t, resp.Bids, 1)
+}
+
+func TestYieldlabAdapter_MakeBids_minViewability(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.MinViewability = 0.5
+
+	request := newTestBidRequest()
+	for _, adslotID := range []string{"67890", "13579"} {
+		request.Imp = append(request.Imp, openrtb2.Imp{
+			ID:     "test-imp-id-" + adslotID,
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+			Ext:    json.RawMessage(`{"bidder":{"adslotId":"` + adslotID + `","supplyId":"123456789","adSize":"728x90"}}`),
+		})
+	}
+
+	resp, errs := runTestAuction(t, bidder, request, nil, `[
+		{"id":12345,"price":201,"adsize":"728x90","pid":1234,"viewability":0.3},
+		{"id":67890,"price":201,"adsize":"728x90","pid":1234,"viewability":0.5},
+		{"id":13579,"price":201,"adsize":"728x90","pid":1234}
+	]`)
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "dropped yieldlab bid for adslotID 12345 as its viewability 0.3 is below the minimum of 0.5", errs[0].Error())
+	}
+	if assert.Len(t, resp.Bids, 2) {
+		assert.Equal(t, "test-imp-id-67890", resp.Bids[0].Bid.ImpID)
+		assert.Equal(t, "test-imp-id-13579", resp.Bids[1].Bid.ImpID)
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_multiFormatFill(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	request.Imp[0].Video = &openrtb2.Video{W: 640, H: 480}
+
+	resp, errs := runTestAuction(t, bidder, request, nil, `[
+		{"id":12345,"price":201,"adsize":"728x90","pid":1234,"format":"banner"},
+		{"id":12345,"price":350,"adsize":"640x480","pid":1234,"format":"video"},
+		{"id":12345,"price":100,"adsize":"300x250","pid":1234,"format":"native"}
+	]`)
+	assert.Empty(t, errs)
+	if assert.Len(t, resp.Bids, 2) {
+		assert.Equal(t, "12345-banner", resp.Bids[0].Bid.ID)
+		assert.Equal(t, openrtb_ext.BidTypeBanner, resp.Bids[0].BidType)
+		assert.Equal(t, request.Imp[0].ID, resp.Bids[0].Bid.ImpID)
+		assert.Equal(t, 2.01, resp.Bids[0].Bid.Price)
+
+		assert.Equal(t, "12345-video", resp.Bids[1].Bid.ID)
+		assert.Equal(t, openrtb_ext.BidTypeVideo, resp.Bids[1].BidType)
+		assert.Equal(t, request.Imp[0].ID, resp.Bids[1].Bid.ImpID)
+		assert.Equal(t, 3.5, resp.Bids[1].Bid.Price)
+	}
+}
+
+func TestYieldlabAdapter_MakeBids_advertiserSummary(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	for _, adslotID := range []string{"67890", "13579"} {
+		request.Imp = append(request.Imp, openrtb2.Imp{
+			ID:     "test-imp-id-" + adslotID,
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+			Ext:    json.RawMessage(`{"bidder":{"adslotId":"` + adslotID + `","supplyId":"123456789","adSize":"728x90"}}`),
+		})
+	}
+	body := `[
+		{"id":12345,"price":201,"advertiser":"yieldlab.de","adsize":"728x90","pid":1234},
+		{"id":67890,"price":150,"advertiser":{"name":"Example","domain":"example.com"},"adsize":"728x90","pid":1234},
+		{"id":13579,"price":100,"advertiser":"yieldlab.de","adsize":"728x90","pid":1234}
+	]`
+
+	resp, errs := runTestAuction(t, bidder, request, nil, body)
+	assert.Empty(t, errs)
+	assert.Empty(t, resp.Ext)
+
+	bidder.extraInfo.AdvertiserSummary = true
+	resp, errs = runTestAuction(t, bidder, request, nil, body)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"advertisers":[
+		{"domain":"yieldlab.de","bids":2},
+		{"name":"Example","domain":"example.com","bids":1}
+	]}`, string(resp.Ext))
+}
+
+func TestYieldlabAdapter_MakeBids_seatPerAdvertiser(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	request := newTestBidRequest()
+	for _, adslotID := range []string{"67890", "13579", "24680"} {
+		request.Imp = append(request.Imp, openrtb2.Imp{
+			ID:     "test-imp-id-" + adslotID,
+			Banner: &openrtb2.Banner{Format: []openrtb2.Format{{W: 728, H: 90}}},
+			Ext:    json.RawMessage(`{"bidder":{"adslotId":"` + adslotID + `","supplyId":"123456789","adSize":"728x90"}}`),
+		})
+	}
+	body := `[
+		{"id":12345,"price":201,"advertiser":"https://www.Yieldlab.de/","adsize":"728x90","pid":1234},
+		{"id":67890,"price":150,"advertiser":{"name":"Example","domain":"example.com"},"adsize":"728x90","pid":1234},
+		{"id":13579,"price":100,"advertiser":"yieldlab.de","adsize":"728x90","pid":1234},
+		{"id":24680,"price":100,"adsize":"728x90","pid":1234}
+	]`
+
+	resp, errs := runTestAuction(t, bidder, request, nil, body)
+	assert.Empty(t, errs)
+	if assert.Len(t, resp.Bids, 4) {
+		for _, bid := range resp.Bids {
+			assert.Empty(t, bid.Seat)
+		}
+	}
+
+	bidder.extraInfo.SeatPerAdvertiser = true
+	resp, errs = runTestAuction(t, bidder, request, nil, body)
+	assert.Empty(t, errs)
+	if assert.Len(t, resp.Bids, 4) {
+		assert.Equal(t, "yieldlab.de", resp.Bids[0].Seat)
+		assert.Equal(t, "Example", resp.Bids[1].Seat)
+		assert.Equal(t, "yieldlab.de", resp.Bids[2].Seat)
+		assert.Empty(t, resp.Bids[3].Seat)
+	}
+}
+
+func TestMakeAdvertiserSeat(t *testing.T) {
+	assert.Equal(t, "Example", makeAdvertiserSeat(advertiser{ID: 42, Name: "Example", Domain: "example.com"}))
+	assert.Equal(t, "example.com", makeAdvertiserSeat(advertiser{ID: 42, Domain: "https://www.example.com/"}))
+	assert.Equal(t, "42", makeAdvertiserSeat(advertiser{ID: 42}))
+	assert.Empty(t, makeAdvertiserSeat(advertiser{}))
+}
+
+func TestYieldlabAdapter_MakeBids_amp(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+	bidder.extraInfo.BidTTL = 300
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Contains(t, resp.Bids[0].Bid.AdM, "ts=testing")
+	assert.Contains(t, resp.Bids[0].Bid.AdM, "exp=1600000300")
+
+	request := newTestBidRequest()
+	request.Site = &openrtb2.Site{Page: "https://example.com/amp.html", Ext: json.RawMessage(`{"amp":1}`)}
+	reqData, errs := bidder.MakeRequests(request, nil)
+	assert.Empty(t, errs)
+	uri, err := url.Parse(reqData[0].Uri)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, uri.Query(), "amp")
+	}
+
+	// the ads of AMP auctions are cacheable, so they have neither cache buster nor expiry
+	bidder.extraInfo.OmitQueryParams = []string{"amp"}
+	resp, errs = runTestAuction(t, bidder, request, nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Equal(t, `<script src="https://ad.yieldlab.net/d/12345/123456789/728x90?id=&pvid=40cb3251-1e1e-4cfd-8edc-7d32dc1a21e5"></script>`, resp.Bids[0].Bid.AdM)
+	assert.Equal(t, int64(300), resp.Bids[0].Bid.Exp)
+}
+
+func TestYieldlabAdapter_MakeBids_adSourceExpiry(t *testing.T) {
+	bidder := newTestYieldlabBidder(testURL)
+
+	resp, errs := runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.NotContains(t, resp.Bids[0].Bid.AdM, "exp=")
+
+	bidder.extraInfo.BidTTL = 300
+	resp, errs = runTestAuction(t, bidder, newTestBidRequest(), nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Contains(t, resp.Bids[0].Bid.AdM, "exp=1600000300")
+
+	request := newTestBidRequest()
+	request.Imp[0].Video = &openrtb2.Video{W: 640, H: 480}
+	request.Imp[0].Exp = 60
+	resp, errs = runTestAuction(t, bidder, request, nil, testResponseBody)
+	assert.Empty(t, errs)
+	assert.Contains(t, resp.Bids[0].Bid.AdM, "exp=1600000060")
+}
@@ -158,14 +158,21 @@ func (deps *cookieSyncDeps) Endpoint(w http.ResponseWriter, r *http.Request, _ h
 	}
 	for i := 0; i < len(parsedReq.Bidders); i++ {
 		bidder := parsedReq.Bidders[i]
-		syncInfo, err := deps.syncers[openrtb_ext.BidderName(bidder)].GetUsersyncInfo(privacyPolicy)
+		syncer := deps.syncers[openrtb_ext.BidderName(bidder)]
+		syncPolicy := privacyPolicy
+		if parsedReq.ambiguousGDPR && keepsAmbiguousGDPRSignal(syncer) {
+			syncPolicy.GDPR.Signal = ""
+		}
+		syncInfos, err := getUsersyncInfos(syncer, syncPolicy)
 		if err == nil {
-			newSync := &usersync.CookieSyncBidders{
-				BidderCode:   bidder,
-				NoCookie:     true,
-				UsersyncInfo: syncInfo,
+			for _, syncInfo := range syncInfos {
+				newSync := &usersync.CookieSyncBidders{
+					BidderCode:   bidder,
+					NoCookie:     true,
+					UsersyncInfo: syncInfo,
+				}
+				csResp.BidderStatus = append(csResp.BidderStatus, newSync)
 			}
-			csResp.BidderStatus = append(csResp.BidderStatus, newSync)
 		} else {
 			glog.Errorf("Failed to get usersync info for %s: %v", bidder, err)
 		}
@@ -191,6 +198,7 @@ func parseRequest(parsedReq *cookieSyncRequest, bodyBytes []byte, usersyncIfAmbi
 	}
 	// If GDPR is ambiguous, lets untangle it here.
 	if parsedReq.GDPR == nil {
+		parsedReq.ambiguousGDPR = true
 		var gdpr = new(int)
 		*gdpr = 1
 		if usersyncIfAmbiguous {
@@ -218,6 +226,25 @@ func parseBidders(request []byte) ([]byte, error) {
 	return nil, nil
 }
 
+// getUsersyncInfos returns the infos of all endpoints the syncer syncs, which is a single one unless it's a MultiUsersyncer
+func getUsersyncInfos(syncer usersync.Usersyncer, privacyPolicy privacy.Policies) ([]*usersync.UsersyncInfo, error) {
+	if multiSyncer, ok := syncer.(usersync.MultiUsersyncer); ok {
+		return multiSyncer.GetUsersyncInfos(privacyPolicy)
+	}
+
+	syncInfo, err := syncer.GetUsersyncInfo(privacyPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return []*usersync.UsersyncInfo{syncInfo}, nil
+}
+
+// keepsAmbiguousGDPRSignal checks if the syncer syncs with an empty GDPR signal if the request doesn't state it
+func keepsAmbiguousGDPRSignal(syncer usersync.Usersyncer) bool {
+	ambiguousSyncer, ok := syncer.(usersync.AmbiguousGDPRUsersyncer)
+	return ok && ambiguousSyncer.KeepsAmbiguousGDPRSignal()
+}
+
 func cookieSyncStatus(syncCount int) string {
 	if syncCount == 0 {
 		return "no_cookie"
@@ -231,6 +258,9 @@ type cookieSyncRequest struct {
 	Consent   string   `json:"gdpr_consent"`
 	USPrivacy string   `json:"us_privacy"`
 	Limit     int      `json:"limit"`
+
+	// ambiguousGDPR is true if the request doesn't state whether GDPR applies
+	ambiguousGDPR bool
 }
 
 func (req *cookieSyncRequest) filterExistingSyncs(valid map[openrtb_ext.BidderName]usersync.Usersyncer, cookie *usersync.PBSCookie, needSyncupForSameSite bool) {
@@ -15,11 +15,13 @@ import (
 	"github.com/prebid/prebid-server/adapters/audienceNetwork"
 	"github.com/prebid/prebid-server/adapters/lifestreet"
 	"github.com/prebid/prebid-server/adapters/pubmatic"
+	"github.com/prebid/prebid-server/adapters/yieldlab"
 	analyticsConf "github.com/prebid/prebid-server/analytics/config"
 	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/gdpr"
 	metricsConf "github.com/prebid/prebid-server/metrics/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/privacy"
 	"github.com/prebid/prebid-server/usersync"
 	"github.com/stretchr/testify/assert"
 )
@@ -172,6 +174,81 @@ func TestCookieSyncWithLargeLimit(t *testing.T) {
 	assert.Equal(t, "no_cookie", parseStatus(t, rr.Body.Bytes()))
 }
 
+func TestCookieSyncMultipleSyncs(t *testing.T) {
+	syncers := map[openrtb_ext.BidderName]usersync.Usersyncer{
+		openrtb_ext.BidderAppnexus: &multiSyncer{Usersyncer: appnexus.NewAppnexusSyncer(template.Must(template.New("sync").Parse("someurl.com")))},
+	}
+	endpoint := NewCookieSyncEndpoint(syncers, &config.Configuration{}, mockPermissions(true, syncers), &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap())
+	req, _ := http.NewRequest("POST", "/cookie_sync", strings.NewReader(`{"bidders":["appnexus"]}`))
+	rr := httptest.NewRecorder()
+	endpoint(rr, req, nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{"appnexus", "appnexus"}, parseSyncs(t, rr.Body.Bytes()))
+	url, err := jsonparser.GetString(rr.Body.Bytes(), "bidder_status", "[0]", "usersync", "url")
+	assert.NoError(t, err)
+	assert.Equal(t, "someurl.com", url)
+	syncType, err := jsonparser.GetString(rr.Body.Bytes(), "bidder_status", "[1]", "usersync", "type")
+	assert.NoError(t, err)
+	assert.Equal(t, "iframe", syncType)
+}
+
+func TestCookieSyncAmbiguousGDPR(t *testing.T) {
+	syncers := map[openrtb_ext.BidderName]usersync.Usersyncer{
+		openrtb_ext.BidderAppnexus: appnexus.NewAppnexusSyncer(template.Must(template.New("sync").Parse("https://adnxs.com/sync?gdpr={{.GDPR}}"))),
+		openrtb_ext.BidderYieldlab: yieldlab.NewYieldlabSyncer(template.Must(template.New("sync").Parse("https://ad.yieldlab.net/mr?gdpr={{.GDPR}}"))),
+	}
+
+	tests := []struct {
+		name            string
+		body            string
+		wantAppnexusURL string
+		wantYieldlabURL string
+	}{
+		{
+			name:            "ambiguous",
+			body:            `{"bidders":["appnexus","yieldlab"]}`,
+			wantAppnexusURL: "https://adnxs.com/sync?gdpr=0",
+			wantYieldlabURL: "https://ad.yieldlab.net/mr?gdpr=",
+		},
+		{
+			name:            "not_applying",
+			body:            `{"bidders":["appnexus","yieldlab"],"gdpr":0}`,
+			wantAppnexusURL: "https://adnxs.com/sync?gdpr=0",
+			wantYieldlabURL: "https://ad.yieldlab.net/mr?gdpr=0",
+		},
+	}
+	cfg := &config.Configuration{GDPR: config.GDPR{UsersyncIfAmbiguous: true}}
+	endpoint := NewCookieSyncEndpoint(syncers, cfg, mockPermissions(true, syncers), &metricsConf.DummyMetricsEngine{}, analyticsConf.NewPBSAnalytics(&config.Analytics{}), openrtb_ext.BuildBidderMap())
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/cookie_sync", strings.NewReader(test.body))
+		rr := httptest.NewRecorder()
+		endpoint(rr, req, nil)
+		assert.Equal(t, http.StatusOK, rr.Code, test.name)
+
+		urls := make(map[string]string)
+		jsonparser.ArrayEach(rr.Body.Bytes(), func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+			bidder, _ := jsonparser.GetString(value, "bidder")
+			urls[bidder], _ = jsonparser.GetString(value, "usersync", "url")
+		}, "bidder_status")
+		assert.Equal(t, test.wantAppnexusURL, urls["appnexus"], test.name)
+		assert.Equal(t, test.wantYieldlabURL, urls["yieldlab"], test.name)
+	}
+}
+
+// multiSyncer syncs an iframe besides the endpoint of the wrapped syncer
+type multiSyncer struct {
+	usersync.Usersyncer
+}
+
+func (s *multiSyncer) GetUsersyncInfos(privacyPolicies privacy.Policies) ([]*usersync.UsersyncInfo, error) {
+	syncInfo, err := s.GetUsersyncInfo(privacyPolicies)
+	if err != nil {
+		return nil, err
+	}
+	return []*usersync.UsersyncInfo{syncInfo, {URL: "iframeurl.com", Type: "iframe"}}, nil
+}
+
 func doPost(body string, existingSyncs map[string]string, gdprHostConsent bool, gdprBidders map[openrtb_ext.BidderName]usersync.Usersyncer) *httptest.ResponseRecorder {
 	return doConfigurablePost(body, existingSyncs, gdprHostConsent, gdprBidders, config.GDPR{}, config.CCPA{})
 }
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptrace"
@@ -71,6 +72,8 @@ type pbsOrtbBid struct {
 	dealPriority      int
 	dealTierSatisfied bool
 	generatedBidID    string
+	// seat is the seat of the bid in the response, it's the bidder name if empty
+	seat string
 }
 
 // pbsOrtbSeatBid is a SeatBid returned by an adaptedBidder.
@@ -85,6 +88,11 @@ type pbsOrtbSeatBid struct {
 	// httpCalls is the list of debugging info. It should only be populated if the request.test == 1.
 	// This will become response.ext.debug.httpcalls.{bidder} on the final Response.
 	httpCalls []*openrtb_ext.ExtHttpCall
+	// ext is the seatbid.ext of the bidder. If the bidder made several requests, the last response with an ext wins.
+	ext json.RawMessage
+	// fledgeAuctionConfigs are the Protected Audience auction configs of all responses of the bidder.
+	// This will become response.ext.prebid.fledge.auctionconfigs on the final Response.
+	fledgeAuctionConfigs []*openrtb_ext.FledgeAuctionConfig
 }
 
 // adaptBidder converts an adapters.Bidder into an exchange.adaptedBidder.
@@ -127,6 +135,9 @@ type bidderAdapterConfig struct {
 }
 
 func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currency.Conversions, reqInfo *adapters.ExtraRequestInfo, accountDebugAllowed bool) (*pbsOrtbSeatBid, []error) {
+	if reqInfo != nil {
+		reqInfo.Debug = isDebugEnabled(ctx) && accountDebugAllowed && bidder.config.DebugInfo.Allow
+	}
 	reqData, errs := bidder.Bidder.MakeRequests(request, reqInfo)
 
 	if len(reqData) == 0 {
@@ -181,10 +192,15 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 		}
 
 		if httpInfo.err == nil {
-			bidResponse, moreErrs := bidder.Bidder.MakeBids(request, httpInfo.request, httpInfo.response)
+			bidResponse, moreErrs := bidder.makeBids(request, httpInfo, reqInfo)
 			errs = append(errs, moreErrs...)
 
 			if bidResponse != nil {
+				if bidResponse.Ext != nil {
+					seatBid.ext = bidResponse.Ext
+				}
+				seatBid.fledgeAuctionConfigs = append(seatBid.fledgeAuctionConfigs, bidResponse.FledgeAuctionConfigs...)
+
 				// Setup default currency as `USD` is not set in bid request nor bid response
 				if bidResponse.Currency == "" {
 					bidResponse.Currency = defaultCurrency
@@ -235,6 +251,7 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb2.B
 							bidType:      bidResponse.Bids[i].BidType,
 							bidVideo:     bidResponse.Bids[i].BidVideo,
 							dealPriority: bidResponse.Bids[i].DealPriority,
+							seat:         bidResponse.Bids[i].Seat,
 						})
 					}
 				} else {
@@ -362,7 +379,11 @@ func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
 // doRequest makes a request, handles the response, and returns the data needed by the
 // Bidder interface.
 func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.RequestData) *httpCallInfo {
-	return bidder.doRequestImpl(ctx, req, glog.Warningf)
+	call := bidder.doRequestImpl(ctx, req, glog.Warningf)
+	if call.err != nil {
+		bidder.notifyFailure(req, call.err)
+	}
+	return call
 }
 
 func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.RequestData, logger util.LogMsg) *httpCallInfo {
@@ -380,7 +401,7 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	if !bidder.config.DisableConnMetrics {
 		ctx = bidder.addClientTrace(ctx)
 	}
-	httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq)
+	httpResp, err := bidder.doWithRetry(ctx, httpReq, req)
 	if err != nil {
 		if err == context.DeadlineExceeded {
 			err = &errortypes.Timeout{Message: err.Error()}
@@ -414,21 +435,115 @@ func (bidder *bidderAdapter) doRequestImpl(ctx context.Context, req *adapters.Re
 	}
 	defer httpResp.Body.Close()
 
-	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 400 {
+	response := &adapters.ResponseData{
+		StatusCode: httpResp.StatusCode,
+		Body:       respBody,
+		Headers:    httpResp.Header,
+	}
+	if (httpResp.StatusCode < 200 || httpResp.StatusCode >= 400) && !bidder.handlesErrorResponse(response) {
 		err = &errortypes.BadServerResponse{
 			Message: fmt.Sprintf("Server responded with failure status: %d. Set request.test = 1 for debugging info.", httpResp.StatusCode),
 		}
 	}
 
 	return &httpCallInfo{
-		request: req,
-		response: &adapters.ResponseData{
-			StatusCode: httpResp.StatusCode,
-			Body:       respBody,
-			Headers:    httpResp.Header,
-		},
-		err: err,
+		request:  req,
+		response: response,
+		err:      err,
+	}
+}
+
+// isDebugEnabled checks if the request enables the debug information
+func isDebugEnabled(ctx context.Context) bool {
+	debugInfo, ok := ctx.Value(DebugContextKey).(bool)
+	return ok && debugInfo
+}
+
+// makeBids makes the bids of the response, passing the ExtraRequestInfo to a RequestInfoAwareBidder
+func (bidder *bidderAdapter) makeBids(request *openrtb2.BidRequest, httpInfo *httpCallInfo, reqInfo *adapters.ExtraRequestInfo) (*adapters.BidderResponse, []error) {
+	var corebidder adapters.Bidder = bidder.Bidder
+	if b, ok := corebidder.(*adapters.InfoAwareBidder); ok {
+		corebidder = b.Bidder
+	}
+	if rb, ok := corebidder.(adapters.RequestInfoAwareBidder); ok {
+		return rb.MakeBidsWithRequestInfo(request, httpInfo.request, httpInfo.response, reqInfo)
+	}
+	return bidder.Bidder.MakeBids(request, httpInfo.request, httpInfo.response)
+}
+
+// handlesErrorResponse checks if the bidder is an ErrorResponseBidder which handles the response in MakeBids
+func (bidder *bidderAdapter) handlesErrorResponse(response *adapters.ResponseData) bool {
+	var corebidder adapters.Bidder = bidder.Bidder
+	if b, ok := corebidder.(*adapters.InfoAwareBidder); ok {
+		corebidder = b.Bidder
+	}
+	if eb, ok := corebidder.(adapters.ErrorResponseBidder); ok {
+		return eb.HandlesErrorResponse(response)
+	}
+	return false
+}
+
+// doWithRetry executes the request. If the bidder is a RetryBidder, the request is retried once within
+// the remaining time of the auction if the first attempt times out.
+func (bidder *bidderAdapter) doWithRetry(ctx context.Context, httpReq *http.Request, req *adapters.RequestData) (*http.Response, error) {
+	timeout := bidder.retryTimeout(req)
+	deadline, hasDeadline := ctx.Deadline()
+	if timeout <= 0 || !hasDeadline || time.Until(deadline) <= timeout {
+		return ctxhttp.Do(ctx, bidder.Client, httpReq)
 	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	httpResp, err := ctxhttp.Do(attemptCtx, bidder.Client, httpReq)
+	if err == nil {
+		// The body is read after the request returns, so the attempt may only be canceled once it's closed.
+		httpResp.Body = &cancelOnCloseBody{ReadCloser: httpResp.Body, cancel: cancel}
+		return httpResp, nil
+	}
+	cancel()
+	if err != context.DeadlineExceeded || ctx.Err() != nil {
+		return nil, err
+	}
+
+	retryReq := httpReq.Clone(ctx)
+	if httpReq.GetBody != nil {
+		if retryReq.Body, err = httpReq.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return ctxhttp.Do(ctx, bidder.Client, retryReq)
+}
+
+func (bidder *bidderAdapter) retryTimeout(req *adapters.RequestData) time.Duration {
+	var corebidder adapters.Bidder = bidder.Bidder
+	if b, ok := corebidder.(*adapters.InfoAwareBidder); ok {
+		corebidder = b.Bidder
+	}
+	if rb, ok := corebidder.(adapters.RetryBidder); ok {
+		return rb.RetryTimeout(req)
+	}
+	return 0
+}
+
+// notifyFailure tells the bidder about the failed request, if it's a FailureAwareBidder
+func (bidder *bidderAdapter) notifyFailure(req *adapters.RequestData, err error) {
+	var corebidder adapters.Bidder = bidder.Bidder
+	if b, ok := corebidder.(*adapters.InfoAwareBidder); ok {
+		corebidder = b.Bidder
+	}
+	if fb, ok := corebidder.(adapters.FailureAwareBidder); ok {
+		fb.RequestFailed(req, err)
+	}
+}
+
+// cancelOnCloseBody cancels the context of a request once its response body is closed
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
 }
 
 func (bidder *bidderAdapter) doTimeoutNotification(timeoutBidder adapters.TimeoutBidder, req *adapters.RequestData, logger util.LogMsg) {
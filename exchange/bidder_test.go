@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"net/http/httptrace"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -86,6 +87,7 @@ func TestSingleBidder(t *testing.T) {
 					},
 					BidType:      openrtb_ext.BidTypeVideo,
 					DealPriority: 5,
+					Seat:         "seat",
 				},
 			},
 		}
@@ -128,6 +130,9 @@ func TestSingleBidder(t *testing.T) {
 			if typedBid.DealPriority != seatBid.bids[index].dealPriority {
 				t.Errorf("Bid %d did not have the right deal priority. Expected %s, got %s", index, typedBid.BidType, seatBid.bids[index].bidType)
 			}
+			if typedBid.Seat != seatBid.bids[index].seat {
+				t.Errorf("Bid %d did not have the right seat. Expected %s, got %s", index, typedBid.Seat, seatBid.bids[index].seat)
+			}
 		}
 		if mockBidderResponse.Bids[0].Bid.Price != bidAdjustment*firstInitialPrice {
 			t.Errorf("Bid[0].Price was not adjusted properly. Expected %f, got %f", bidAdjustment*firstInitialPrice, mockBidderResponse.Bids[0].Bid.Price)
@@ -319,6 +324,210 @@ func TestConnectionClose(t *testing.T) {
 	}
 }
 
+func TestSeatBidExt(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", `{"bid":true}`))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte(`{"key":"val"}`),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{{Bid: &openrtb2.Bid{Price: 1}, BidType: openrtb_ext.BidTypeBanner}},
+			Ext:  json.RawMessage(`{"advertisers":["yieldlab"]}`),
+		},
+	}
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true)
+	assert.Empty(t, errs)
+	assert.JSONEq(t, `{"advertisers":["yieldlab"]}`, string(seatBid.ext))
+}
+
+func TestSeatBidFledgeAuctionConfigs(t *testing.T) {
+	server := httptest.NewServer(mockHandler(200, "getBody", `{"bid":true}`))
+	defer server.Close()
+
+	fledgeConfig := &openrtb_ext.FledgeAuctionConfig{ImpId: "imp-1", Config: json.RawMessage(`{"seller":"https://example.com"}`)}
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte(`{"key":"val"}`),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids:                 []*adapters.TypedBid{{Bid: &openrtb2.Bid{Price: 1}, BidType: openrtb_ext.BidTypeBanner}},
+			FledgeAuctionConfigs: []*openrtb_ext.FledgeAuctionConfig{fledgeConfig},
+		},
+	}
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, nil)
+	currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, true)
+	assert.Empty(t, errs)
+	assert.Equal(t, []*openrtb_ext.FledgeAuctionConfig{fledgeConfig}, seatBid.fledgeAuctionConfigs)
+}
+
+func TestRequestFailedNotification(t *testing.T) {
+	testCases := []struct {
+		description     string
+		status          int
+		expectedFailure bool
+	}{
+		{
+			description: "Success isn't notified",
+			status:      200,
+		},
+		{
+			description: "No content isn't notified",
+			status:      204,
+		},
+		{
+			description:     "Failure status is notified",
+			status:          503,
+			expectedFailure: true,
+		},
+	}
+
+	for _, test := range testCases {
+		server := httptest.NewServer(mockHandler(test.status, "getBody", `{"bid":true}`))
+
+		failureBidder := &failureAwareBidder{}
+		bidder := &bidderAdapter{
+			Bidder: wrapWithBidderInfo(failureBidder),
+			Client: server.Client(),
+			me:     &metricsConfig.DummyMetricsEngine{},
+		}
+
+		bidder.doRequest(context.Background(), &adapters.RequestData{
+			Method: "POST",
+			Uri:    server.URL,
+		})
+		if test.expectedFailure {
+			assert.Len(t, failureBidder.failures, 1, test.description)
+		} else {
+			assert.Empty(t, failureBidder.failures, test.description)
+		}
+
+		server.Close()
+	}
+}
+
+func TestErrorResponseHandling(t *testing.T) {
+	testCases := []struct {
+		description    string
+		status         int
+		header         string
+		expectedErr    bool
+		expectedStatus int
+	}{
+		{
+			description:    "Handled failure status is passed to the bidder",
+			status:         429,
+			header:         "rate_limited",
+			expectedStatus: 429,
+		},
+		{
+			description: "Unhandled failure status fails the request",
+			status:      503,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if test.header != "" {
+				w.Header().Set("X-Error-Code", test.header)
+			}
+			w.WriteHeader(test.status)
+		}))
+
+		errorBidder := &errorResponseBidder{}
+		bidder := &bidderAdapter{
+			Bidder: wrapWithBidderInfo(errorBidder),
+			Client: server.Client(),
+			me:     &metricsConfig.DummyMetricsEngine{},
+		}
+
+		call := bidder.doRequest(context.Background(), &adapters.RequestData{
+			Method: "POST",
+			Uri:    server.URL,
+		})
+		if test.expectedErr {
+			assert.IsType(t, &errortypes.BadServerResponse{}, call.err, test.description)
+		} else {
+			assert.NoError(t, call.err, test.description)
+			assert.Equal(t, test.expectedStatus, call.response.StatusCode, test.description)
+			assert.Empty(t, errorBidder.failures, test.description)
+		}
+
+		server.Close()
+	}
+}
+
+func TestRequestInfoDebug(t *testing.T) {
+	testCases := []struct {
+		description         string
+		debugRequested      bool
+		accountDebugAllowed bool
+		bidderDebugAllowed  bool
+		expectedDebug       bool
+	}{
+		{
+			description:         "Debug requested and allowed",
+			debugRequested:      true,
+			accountDebugAllowed: true,
+			bidderDebugAllowed:  true,
+			expectedDebug:       true,
+		},
+		{
+			description:         "Debug not requested",
+			accountDebugAllowed: true,
+			bidderDebugAllowed:  true,
+		},
+		{
+			description:        "Debug disallowed by the account",
+			debugRequested:     true,
+			bidderDebugAllowed: true,
+		},
+		{
+			description:         "Debug disallowed by the bidder",
+			debugRequested:      true,
+			accountDebugAllowed: true,
+		},
+	}
+
+	server := httptest.NewServer(mockHandler(200, "getBody", `{"bid":true}`))
+	defer server.Close()
+
+	for _, test := range testCases {
+		bidderImpl := &requestInfoAwareBidder{
+			goodSingleBidder: goodSingleBidder{
+				httpRequest: &adapters.RequestData{
+					Method:  "POST",
+					Uri:     server.URL,
+					Headers: http.Header{},
+				},
+				bidResponse: &adapters.BidderResponse{},
+			},
+		}
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConfig.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus, &config.DebugInfo{Allow: test.bidderDebugAllowed})
+		currencyConverter := currency.NewRateConverter(&http.Client{}, "", time.Duration(0))
+		ctx := context.WithValue(context.Background(), DebugContextKey, test.debugRequested)
+
+		_, errs := bidder.requestBid(ctx, &openrtb2.BidRequest{}, "test", 1, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, test.accountDebugAllowed)
+		assert.Empty(t, errortypes.FatalOnly(errs), test.description)
+		if assert.NotNil(t, bidderImpl.reqInfo, test.description) {
+			assert.Equal(t, test.expectedDebug, bidderImpl.reqInfo.Debug, test.description)
+		}
+	}
+}
+
 type bid struct {
 	currency string
 	price    float64
@@ -1633,6 +1842,83 @@ func TestTimeoutNotificationOn(t *testing.T) {
 	assert.EqualValues(t, logExpected, logActual)
 }
 
+func TestRetryOnTimeout(t *testing.T) {
+	testCases := []struct {
+		description   string
+		retryTimeout  time.Duration
+		budget        time.Duration
+		slowCalls     int32
+		expectedCalls int32
+		expectTimeout bool
+	}{
+		{
+			description:   "Retry succeeds within the budget",
+			retryTimeout:  50 * time.Millisecond,
+			budget:        time.Second,
+			slowCalls:     1,
+			expectedCalls: 2,
+		},
+		{
+			description:   "Retry times out at the end of the budget",
+			retryTimeout:  50 * time.Millisecond,
+			budget:        150 * time.Millisecond,
+			slowCalls:     2,
+			expectedCalls: 2,
+			expectTimeout: true,
+		},
+		{
+			description:   "No retry if the budget is shorter than the retry timeout",
+			retryTimeout:  500 * time.Millisecond,
+			budget:        100 * time.Millisecond,
+			slowCalls:     1,
+			expectedCalls: 1,
+			expectTimeout: true,
+		},
+		{
+			description:   "No retry if disabled",
+			budget:        100 * time.Millisecond,
+			slowCalls:     1,
+			expectedCalls: 1,
+			expectTimeout: true,
+		},
+	}
+
+	for _, test := range testCases {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) <= test.slowCalls {
+				time.Sleep(300 * time.Millisecond)
+			}
+			w.Write([]byte(`{"bid":true}`))
+		}))
+
+		bidder := &bidderAdapter{
+			Bidder: wrapWithBidderInfo(&retryingBidder{retryTimeout: test.retryTimeout}),
+			Client: server.Client(),
+			me:     &metricsConfig.DummyMetricsEngine{},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), test.budget)
+		start := time.Now()
+		call := bidder.doRequest(ctx, &adapters.RequestData{
+			Method: "POST",
+			Uri:    server.URL,
+			Body:   []byte(`{"id":"this-id"}`),
+		})
+		elapsed := time.Since(start)
+		cancel()
+		server.Close()
+
+		assert.Equal(t, test.expectedCalls, atomic.LoadInt32(&calls), test.description)
+		assert.LessOrEqual(t, int64(elapsed), int64(test.budget+50*time.Millisecond), test.description)
+		if test.expectTimeout {
+			assert.IsType(t, &errortypes.Timeout{}, call.err, test.description)
+		} else if assert.NoError(t, call.err, test.description) {
+			assert.Equal(t, `{"bid":true}`, string(call.response.Body), test.description)
+		}
+	}
+}
+
 func TestParseDebugInfoTrue(t *testing.T) {
 	debugInfo := &config.DebugInfo{Allow: true}
 	resDebugInfo := parseDebugInfo(debugInfo)
@@ -1736,6 +2022,56 @@ func (bidder *bidRejector) MakeBids(internalRequest *openrtb2.BidRequest, extern
 	return nil, []error{errors.New("Can't make a response.")}
 }
 
+type retryingBidder struct {
+	retryTimeout time.Duration
+}
+
+func (bidder *retryingBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	return nil, nil
+}
+
+func (bidder *retryingBidder) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	return nil, nil
+}
+
+func (bidder *retryingBidder) RetryTimeout(req *adapters.RequestData) time.Duration {
+	return bidder.retryTimeout
+}
+
+type failureAwareBidder struct {
+	failures []error
+}
+
+func (bidder *failureAwareBidder) MakeRequests(request *openrtb2.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	return nil, nil
+}
+
+func (bidder *failureAwareBidder) MakeBids(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	return nil, nil
+}
+
+func (bidder *failureAwareBidder) RequestFailed(req *adapters.RequestData, err error) {
+	bidder.failures = append(bidder.failures, err)
+}
+
+type requestInfoAwareBidder struct {
+	goodSingleBidder
+	reqInfo *adapters.ExtraRequestInfo
+}
+
+func (bidder *requestInfoAwareBidder) MakeBidsWithRequestInfo(internalRequest *openrtb2.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData, reqInfo *adapters.ExtraRequestInfo) (*adapters.BidderResponse, []error) {
+	bidder.reqInfo = reqInfo
+	return bidder.MakeBids(internalRequest, externalRequest, response)
+}
+
+type errorResponseBidder struct {
+	failureAwareBidder
+}
+
+func (bidder *errorResponseBidder) HandlesErrorResponse(response *adapters.ResponseData) bool {
+	return response.Headers.Get("X-Error-Code") != ""
+}
+
 type notifyingBidder struct {
 	requests      []*adapters.RequestData
 	notifyRequest adapters.RequestData
@@ -33,6 +33,9 @@ type ContextKey string
 
 const DebugContextKey = ContextKey("debugInfo")
 
+// TraceIDContextKey is the context key of a string ID a host may set to trace the auction, which is passed on to the bidders
+const TraceIDContextKey = ContextKey("traceID")
+
 type extCacheInstructions struct {
 	cacheBids, cacheVAST, returnCreative bool
 }
@@ -433,6 +436,13 @@ func (e *exchange) getAllBids(
 			}
 			var reqInfo adapters.ExtraRequestInfo
 			reqInfo.PbsEntryPoint = bidderRequest.BidderLabels.RType
+			reqInfo.CurrencyConversions = conversions
+			if traceID, ok := ctx.Value(TraceIDContextKey).(string); ok {
+				reqInfo.TraceID = traceID
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				reqInfo.Deadline = deadline
+			}
 			bids, err := e.adapterMap[bidderRequest.BidderCoreName].requestBid(ctx, bidderRequest.BidRequest, bidderRequest.BidderName, adjustmentFactor, conversions, &reqInfo, accountDebugAllowed)
 
 			// Add in time reporting
@@ -586,8 +596,7 @@ func (e *exchange) buildBidResponse(ctx context.Context, liveAdapters []openrtb_
 	for _, a := range liveAdapters {
 		//while processing every single bib, do we need to handle categories here?
 		if adapterBids[a] != nil && len(adapterBids[a].bids) > 0 {
-			sb := e.makeSeatBid(adapterBids[a], a, adapterExtra, auc, returnCreative)
-			seatBids = append(seatBids, *sb)
+			seatBids = append(seatBids, e.makeSeatBids(adapterBids[a], a, adapterExtra, auc, returnCreative)...)
 			bidResponse.Cur = adapterBids[a].currency
 		}
 	}
@@ -827,11 +836,16 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*pb
 		}
 	}
 	if !r.StartTime.IsZero() {
-		// auctiontimestamp is the only response.ext.prebid attribute we may emit
 		bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{
 			AuctionTimestamp: r.StartTime.UnixNano() / 1e+6,
 		}
 	}
+	if fledge := makeFledge(adapterBids); fledge != nil {
+		if bidResponseExt.Prebid == nil {
+			bidResponseExt.Prebid = &openrtb_ext.ExtResponsePrebid{}
+		}
+		bidResponseExt.Prebid.Fledge = fledge
+	}
 
 	for bidderName, responseExtra := range adapterExtra {
 
@@ -855,21 +869,69 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*pb
 	return bidResponseExt
 }
 
+// makeFledge collects the Protected Audience auction configs of all bidders, naming the bidder of each config.
+// The bidders are visited in the order of their names so the configs are listed in a stable order.
+func makeFledge(adapterBids map[openrtb_ext.BidderName]*pbsOrtbSeatBid) *openrtb_ext.Fledge {
+	bidderNames := make([]string, 0, len(adapterBids))
+	for bidderName := range adapterBids {
+		bidderNames = append(bidderNames, bidderName.String())
+	}
+	sort.Strings(bidderNames)
+
+	var fledge *openrtb_ext.Fledge
+	for _, bidderName := range bidderNames {
+		seatBid := adapterBids[openrtb_ext.BidderName(bidderName)]
+		if seatBid == nil {
+			continue
+		}
+		for _, config := range seatBid.fledgeAuctionConfigs {
+			if config == nil {
+				continue
+			}
+			if fledge == nil {
+				fledge = &openrtb_ext.Fledge{}
+			}
+			config.Bidder = bidderName
+			fledge.AuctionConfigs = append(fledge.AuctionConfigs, config)
+		}
+	}
+	return fledge
+}
+
 // Return an openrtb seatBid for a bidder
 // BuildBidResponse is responsible for ensuring nil bid seatbids are not included
-func (e *exchange) makeSeatBid(adapterBid *pbsOrtbSeatBid, adapter openrtb_ext.BidderName, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, auc *auction, returnCreative bool) *openrtb2.SeatBid {
-	seatBid := &openrtb2.SeatBid{
-		Seat:  adapter.String(),
-		Group: 0, // Prebid cannot support roadblocking
+// makeSeatBids makes the seatbids of the bidder. The bids are grouped by their seat, bids without seat are grouped
+// under the bidder name. The seats are ordered by their first bid and all of them get the seatbid.ext of the bidder.
+func (e *exchange) makeSeatBids(adapterBid *pbsOrtbSeatBid, adapter openrtb_ext.BidderName, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, auc *auction, returnCreative bool) []openrtb2.SeatBid {
+	var seats []string
+	bidsBySeat := make(map[string][]*pbsOrtbBid)
+	for _, bid := range adapterBid.bids {
+		seat := bid.seat
+		if seat == "" {
+			seat = adapter.String()
+		}
+		if _, ok := bidsBySeat[seat]; !ok {
+			seats = append(seats, seat)
+		}
+		bidsBySeat[seat] = append(bidsBySeat[seat], bid)
 	}
 
-	var errList []error
-	seatBid.Bid, errList = e.makeBid(adapterBid.bids, auc, returnCreative)
-	if len(errList) > 0 {
-		adapterExtra[adapter].Errors = append(adapterExtra[adapter].Errors, errsToBidderErrors(errList)...)
-	}
+	seatBids := make([]openrtb2.SeatBid, 0, len(seats))
+	for _, seat := range seats {
+		seatBid := openrtb2.SeatBid{
+			Seat:  seat,
+			Group: 0, // Prebid cannot support roadblocking
+			Ext:   adapterBid.ext,
+		}
 
-	return seatBid
+		var errList []error
+		seatBid.Bid, errList = e.makeBid(bidsBySeat[seat], auc, returnCreative)
+		if len(errList) > 0 {
+			adapterExtra[adapter].Errors = append(adapterExtra[adapter].Errors, errsToBidderErrors(errList)...)
+		}
+		seatBids = append(seatBids, seatBid)
+	}
+	return seatBids
 }
 
 func (e *exchange) makeBid(bids []*pbsOrtbBid, auc *auction, returnCreative bool) ([]openrtb2.Bid, []error) {
@@ -919,6 +981,15 @@ func makeBidExtJSON(ext json.RawMessage, prebid *openrtb_ext.ExtBidPrebid) (json
 	if err := json.Unmarshal(ext, &extMap); err != nil {
 		return nil, err
 	}
+
+	// keep the bid.ext.prebid.meta provided by the bidder, as it is the only source of this information
+	if prebid.Meta == nil {
+		var bidExt openrtb_ext.ExtBid
+		if err := json.Unmarshal(ext, &bidExt); err == nil && bidExt.Prebid != nil {
+			prebid.Meta = bidExt.Prebid.Meta
+		}
+	}
+
 	extMap[openrtb_ext.PrebidExtKey] = prebid
 	return json.Marshal(extMap)
 }
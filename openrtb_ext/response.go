@@ -1,6 +1,10 @@
 package openrtb_ext
 
-import "github.com/mxmCherry/openrtb/v15/openrtb2"
+import (
+	"encoding/json"
+
+	"github.com/mxmCherry/openrtb/v15/openrtb2"
+)
 
 // ExtBidResponse defines the contract for bidresponse.ext
 type ExtBidResponse struct {
@@ -37,7 +41,21 @@ type ExtResponseSyncData struct {
 
 // ExtResponsePrebid defines the contract for bidresponse.ext.prebid
 type ExtResponsePrebid struct {
-	AuctionTimestamp int64 `json:"auctiontimestamp,omitempty"`
+	AuctionTimestamp int64   `json:"auctiontimestamp,omitempty"`
+	Fledge           *Fledge `json:"fledge,omitempty"`
+}
+
+// Fledge defines the contract for bidresponse.ext.prebid.fledge
+type Fledge struct {
+	AuctionConfigs []*FledgeAuctionConfig `json:"auctionconfigs,omitempty"`
+}
+
+// FledgeAuctionConfig defines the contract for bidresponse.ext.prebid.fledge.auctionconfigs[i],
+// the Protected Audience auction config a bidder returned for an impression
+type FledgeAuctionConfig struct {
+	ImpId  string          `json:"impid"`
+	Bidder string          `json:"bidder,omitempty"`
+	Config json.RawMessage `json:"config"`
 }
 
 // ExtUserSync defines the contract for bidresponse.ext.usersync.{bidder}.syncs[i]
@@ -16,6 +16,24 @@ type Usersyncer interface {
 	FamilyName() string
 }
 
+// MultiUsersyncer is used to identify Usersyncers which sync several endpoints, like a pixel besides a redirect.
+type MultiUsersyncer interface {
+	Usersyncer
+
+	// GetUsersyncInfos returns the info of all endpoints to sync, starting with the one of GetUsersyncInfo.
+	// The returned UsersyncInfo objects must not be mutated by callers.
+	GetUsersyncInfos(privacyPolicies privacy.Policies) ([]*UsersyncInfo, error)
+}
+
+// AmbiguousGDPRUsersyncer is used to identify Usersyncers which sync with an empty GDPR signal if the request doesn't
+// state whether GDPR applies, instead of the signal assumed by the gdpr.usersync_if_ambiguous config.
+type AmbiguousGDPRUsersyncer interface {
+	Usersyncer
+
+	// KeepsAmbiguousGDPRSignal returns true if the syncer is passed the empty GDPR signal of ambiguous requests.
+	KeepsAmbiguousGDPRSignal() bool
+}
+
 type UsersyncInfo struct {
 	URL         string `json:"url,omitempty"`
 	Type        string `json:"type,omitempty"`